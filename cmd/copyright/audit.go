@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"biblebrain-services/config"
+	connection_service "biblebrain-services/service/connection"
+	copyright_service "biblebrain-services/service/copyright"
+	logo_service "biblebrain-services/service/logo"
+)
+
+var errInvalidAuditFormat = errors.New("invalid format, only 'json' or 'csv' is supported")
+
+// runAudit implements the "audit" command.
+func runAudit(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	products := flags.String("products", "", "comma-separated product codes to audit (default: all products)")
+	mode := flags.String("mode", "", "restrict the audit to 'audio', 'video' or 'text' filesets (default: all modes)")
+	format := flags.String("format", "json", "report format: 'json' or 'csv'")
+	checkLogos := flags.Bool("logos", true, "download and decode organization logos")
+	outPath := flags.String("out", "", "output file (default: stdout)")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("%w: %q", errInvalidAuditFormat, *format)
+	}
+
+	opts := copyright_service.AuditOptions{ProductCodes: splitList(*products), Mode: *mode, CheckLogos: *checkLogos}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	sqlCon, err := connection_service.GetBibleBrainDB(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer sqlCon.Close()
+
+	logos, err := logo_service.New(ctx, cfg.Copyright)
+	if err != nil {
+		return fmt.Errorf("configuring logo cache: %w", err)
+	}
+
+	mgr := copyright_service.New(sqlCon, cfg.Copyright)
+	mgr.Logos = logos
+
+	report, err := mgr.Audit(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("auditing copyrights: %w", err)
+	}
+
+	return writeOutput(*outPath, func(out io.Writer) error {
+		if *format == "csv" {
+			if err := report.WriteCSV(out); err != nil {
+				return fmt.Errorf("writing CSV report: %w", err)
+			}
+
+			return nil
+		}
+
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("writing JSON report: %w", err)
+		}
+
+		return nil
+	})
+}
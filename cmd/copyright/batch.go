@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	connection_service "biblebrain-services/service/connection"
+	copyright_service "biblebrain-services/service/copyright"
+	logo_service "biblebrain-services/service/logo"
+)
+
+// defaultBatchWorkers is the default number of packages generated at once.
+const defaultBatchWorkers = 4
+
+// defaultReportName is the report file in -out-dir; package names cannot start with a dot,
+// so no package overwrites it.
+const defaultReportName = ".report.json"
+
+var (
+	errBatchFailures = errors.New("some packages failed")
+	errInvalidWorker = errors.New("-workers must be positive")
+	errReportPath    = errors.New("-report is the output of a package")
+)
+
+// runBatch implements the "batch" command.
+func runBatch(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	manifestPath := flags.String("manifest", "", "CSV or JSON manifest of packages (required)")
+	outDir := flags.String("out-dir", ".", "directory of the generated packages")
+	inPath := flags.String("input", "", "JSON file of copyrights, as output by -format json, read instead of the database")
+	workers := flags.Int("workers", defaultBatchWorkers, "packages generated at once")
+	pageSize := flags.String("page-size", "", "page size: 'A4' or 'Letter' (default: A4)")
+	reportPath := flags.String("report", "", "summary report file (default: .report.json in -out-dir)")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	if *workers <= 0 {
+		return errInvalidWorker
+	}
+
+	layout := copyright_service.Layout{PageSize: *pageSize}
+	if err := layout.Validate(); err != nil {
+		return err
+	}
+
+	packages, err := readManifest(*manifestPath)
+	if err != nil {
+		return err
+	}
+
+	if *reportPath == "" {
+		*reportPath = filepath.Join(*outDir, defaultReportName)
+	}
+
+	for _, pkg := range packages {
+		if filepath.Clean(*reportPath) == filepath.Join(*outDir, pkg.FileName()) {
+			return fmt.Errorf("%w: %q", errReportPath, pkg.Name)
+		}
+	}
+
+	if err := os.MkdirAll(*outDir, copyright_service.DirPerm); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	cfg, err := loadConfig(*inPath != "")
+	if err != nil {
+		return err
+	}
+
+	source := batchSource{}
+
+	if *inPath == "" {
+		sqlCon, err := connection_service.GetBibleBrainDB(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("connecting to database: %w", err)
+		}
+		defer sqlCon.Close()
+
+		source.mgr = copyright_service.New(sqlCon, cfg.Copyright)
+	} else {
+		if source.saved, err = os.ReadFile(*inPath); err != nil {
+			return fmt.Errorf("reading input %q: %w", *inPath, err)
+		}
+
+		source.mgr = copyright_service.New(nil, cfg.Copyright)
+	}
+
+	// One logo cache serves every package, so each logo is downloaded once per batch.
+	if source.mgr.Logos, err = logo_service.New(ctx, cfg.Copyright); err != nil {
+		return fmt.Errorf("configuring logo cache: %w", err)
+	}
+
+	results := make([]copyright_service.BatchResult, len(packages))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(*workers, len(packages)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				results[i] = source.generate(ctx, packages[i], *outDir, layout)
+			}
+		}()
+	}
+
+	for i := range packages {
+		if ctx.Err() != nil {
+			results[i] = copyright_service.BatchResult{
+				Name: packages[i].Name, Status: copyright_service.BatchFailed, Error: ctx.Err().Error(),
+			}
+
+			continue
+		}
+
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	report := copyright_service.NewBatchReport(results)
+
+	if err := writeReport(*reportPath, report); err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%w: %d of %d, see %s", errBatchFailures, report.Failed, report.Packages, *reportPath)
+	}
+
+	return nil
+}
+
+// readManifest reads the manifest at manifestPath, as CSV if its extension is .csv and as
+// JSON otherwise.
+func readManifest(manifestPath string) ([]copyright_service.BatchPackage, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening manifest: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(manifestPath), ".csv") {
+		return copyright_service.ReadManifestCSV(file)
+	}
+
+	return copyright_service.ReadManifestJSON(file)
+}
+
+// batchSource looks up the copyrights of packages in the database of mgr, or in saved, the
+// content of a JSON file of copyrights, when it is set.
+type batchSource struct {
+	mgr   *copyright_service.Manager
+	saved []byte
+}
+
+// generate writes pkg to outDir and reports the outcome.
+func (s batchSource) generate(
+	ctx context.Context,
+	pkg copyright_service.BatchPackage,
+	outDir string,
+	layout copyright_service.Layout,
+) copyright_service.BatchResult {
+	result := copyright_service.BatchResult{Name: pkg.Name}
+
+	// Managers are cheap; this one differs from the shared one only by its language.
+	mgr := *s.mgr
+	if pkg.LanguageID != 0 {
+		mgr.Config.LanguageID = pkg.LanguageID
+	}
+
+	var (
+		copyrights []copyright_service.ByOrganizations
+		err        error
+	)
+
+	if s.saved == nil {
+		copyrights, err = mgr.GetCopyrightBy(ctx, pkg.Products, pkg.Mode)
+	} else {
+		copyrights, err = copyright_service.ReadCopyrights(bytes.NewReader(s.saved), pkg.Products)
+	}
+
+	result.MissingProducts = copyright_service.MissingProducts(pkg.Products, copyrights)
+
+	switch {
+	case errors.Is(err, copyright_service.ErrProductsNotFound) || err == nil && len(copyrights) == 0:
+		result.Status = copyright_service.BatchMissing
+
+		return result
+	case err != nil:
+		result.Status = copyright_service.BatchFailed
+		result.Error = fmt.Sprintf("loading copyrights: %v", err)
+
+		return result
+	}
+
+	result.Output = filepath.Join(outDir, pkg.FileName())
+
+	result.Bytes, err = writeFile(result.Output, func(file *os.File) error {
+		return writePackage(ctx, file, &mgr, copyrights, pkg.Format, pkg.Mode, layout)
+	})
+	if err != nil {
+		result.Status = copyright_service.BatchFailed
+		result.Output = ""
+		result.Error = err.Error()
+
+		return result
+	}
+
+	result.Status = copyright_service.BatchGenerated
+
+	return result
+}
+
+// writeReport writes report as indented JSON to reportPath.
+func writeReport(reportPath string, report copyright_service.BatchReport) error {
+	return writeOutput(reportPath, func(out io.Writer) error {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("writing report: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"biblebrain-services/config"
+	connection_service "biblebrain-services/service/connection"
+	copyright_service "biblebrain-services/service/copyright"
+	logo_service "biblebrain-services/service/logo"
+)
+
+var (
+	errInvalidFormat    = errors.New("invalid format, only 'pdf' or 'json' is supported")
+	errInvalidMode      = errors.New("invalid mode, only 'audio', 'video' or 'text' is supported")
+	errProductsRequired = errors.New("-products is required unless -input is given")
+)
+
+// runGenerate implements the "generate" command.
+func runGenerate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	products := flags.String("products", "", "comma-separated product codes (with -input: restricts the file to them)")
+	mode := flags.String("mode", copyright_service.ModeAudio, "'audio', 'video' or 'text'")
+	format := flags.String("format", copyright_service.FormatPDF, "output format: 'pdf' or 'json'")
+	inPath := flags.String("input", "", "JSON file of copyrights, as output by -format json, read instead of the database")
+	languageID := flags.Uint("language", 0, "language ID of organization names (default: the configured one)")
+	pageSize := flags.String("page-size", "", "page size: 'A4' or 'Letter' (default: A4)")
+	gridSize := flags.Int("grid-size", 0, "cards per page: 4 or 8 (default: by mode)")
+	outPath := flags.String("out", "", "output file (default: stdout)")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	productCodes := splitList(*products)
+	layout := copyright_service.Layout{PageSize: *pageSize, GridSize: *gridSize}
+
+	if err := validatePackage(*format, *mode, layout); err != nil {
+		return err
+	}
+
+	if *inPath == "" && len(productCodes) == 0 {
+		return errProductsRequired
+	}
+
+	cfg, err := loadConfig(*inPath != "")
+	if err != nil {
+		return err
+	}
+
+	if *languageID != 0 {
+		cfg.Copyright.LanguageID = uint32(*languageID)
+	}
+
+	var sqlCon *sql.DB
+
+	if *inPath == "" {
+		sqlCon, err = connection_service.GetBibleBrainDB(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("connecting to database: %w", err)
+		}
+		defer sqlCon.Close()
+	}
+
+	logos, err := logo_service.New(ctx, cfg.Copyright)
+	if err != nil {
+		return fmt.Errorf("configuring logo cache: %w", err)
+	}
+
+	mgr := copyright_service.New(sqlCon, cfg.Copyright)
+	mgr.Logos = logos
+
+	var copyrights []copyright_service.ByOrganizations
+
+	if *inPath == "" {
+		copyrights, err = mgr.GetCopyrightBy(ctx, productCodes, *mode)
+		if err == nil && len(copyrights) == 0 {
+			err = copyright_service.ErrProductsNotFound
+		}
+	} else {
+		copyrights, err = readCopyrights(*inPath, productCodes)
+	}
+
+	if err != nil {
+		return fmt.Errorf("loading copyrights: %w", err)
+	}
+
+	return writeOutput(*outPath, func(out io.Writer) error {
+		return writePackage(ctx, out, mgr, copyrights, *format, *mode, layout)
+	})
+}
+
+// loadConfig loads the configuration. Offline, the DSN is never read, so unless another
+// secret provider is configured the env provider is used, which requires no DSN settings.
+func loadConfig(offline bool) (*config.Config, error) {
+	lookupEnv := os.LookupEnv
+	if offline {
+		lookupEnv = func(key string) (string, bool) {
+			value, ok := os.LookupEnv(key)
+			if !ok && key == "SECRET_PROVIDER" {
+				return config.SecretProviderEnv, true
+			}
+
+			return value, ok
+		}
+	}
+
+	cfg, err := config.LoadFrom(os.Getenv("CONFIG_FILE"), lookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("loading configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// validatePackage checks the format, mode and layout of a package.
+func validatePackage(format, mode string, layout copyright_service.Layout) error {
+	if format != copyright_service.FormatPDF && format != copyright_service.FormatJSON {
+		return fmt.Errorf("%w: %q", errInvalidFormat, format)
+	}
+
+	if mode != copyright_service.ModeAudio && mode != copyright_service.ModeVideo && mode != copyright_service.ModeText {
+		return fmt.Errorf("%w: %q", errInvalidMode, mode)
+	}
+
+	return layout.Validate()
+}
+
+// readCopyrights reads the copyrights of productCodes, or all, from the JSON file at inPath.
+func readCopyrights(inPath string, productCodes []string) ([]copyright_service.ByOrganizations, error) {
+	file, err := os.Open(inPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening input %q: %w", inPath, err)
+	}
+	defer file.Close()
+
+	return copyright_service.ReadCopyrights(file, productCodes)
+}
+
+// writePackage writes copyrights to out in format, as the API would.
+func writePackage(
+	ctx context.Context,
+	out io.Writer,
+	mgr *copyright_service.Manager,
+	copyrights []copyright_service.ByOrganizations,
+	format, mode string,
+	layout copyright_service.Layout,
+) error {
+	if format == copyright_service.FormatJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(mgr.MarkLogoPlaceholders(ctx, copyrights)); err != nil {
+			return fmt.Errorf("writing JSON: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := mgr.ProducePdfCopyright(ctx, out, copyrights, layout.ForMode(mode)); err != nil {
+		return fmt.Errorf("generating PDF: %w", err)
+	}
+
+	return nil
+}
+
+// splitList returns the non-empty, trimmed items of a comma-separated flag.
+func splitList(list string) []string {
+	var items []string
+
+	for item := range strings.SplitSeq(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
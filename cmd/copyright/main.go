@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	util "biblebrain-services/util"
+)
+
+const usage = `Usage: copyright <command> [flags]
+
+Commands:
+  audit       scan copyright records and report data-quality issues
+  batch       generate every package of a CSV or JSON manifest into a directory
+  generate    render the copyright PDF or JSON of products, from the database or a JSON file
+  prefetch    download and normalize organization logos into the logo cache
+
+Run "copyright <command> -h" for the flags of a command.
+`
+
+func main() {
+	logger := util.Logger(os.Getenv("LOG_LEVEL"))
+	slog.SetDefault(logger)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+
+	switch os.Args[1] {
+	case "audit":
+		err = runAudit(ctx, os.Args[2:])
+	case "batch":
+		err = runBatch(ctx, os.Args[2:])
+	case "generate":
+		err = runGenerate(ctx, os.Args[2:])
+	case "prefetch":
+		err = runPrefetch(ctx, os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		stop()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		slog.Error("command failed", "command", os.Args[1], "error", err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// outputPerm is the mode of new output files, which are published or served as they are.
+const outputPerm = 0o644
+
+// writeOutput writes the -out flag with write; an empty path or "-" means stdout, and a file
+// is only replaced once write succeeds.
+func writeOutput(outPath string, write func(out io.Writer) error) error {
+	if outPath == "" || outPath == "-" {
+		return write(os.Stdout)
+	}
+
+	_, err := writeFile(outPath, func(file *os.File) error { return write(file) })
+
+	return err
+}
+
+// writeFile writes outPath with write through a temporary file, so that a failed write
+// leaves no partial file, and returns its size. The file keeps the mode of the one it
+// replaces, or gets outputPerm.
+func writeFile(outPath string, write func(file *os.File) error) (int64, error) {
+	file, err := os.CreateTemp(filepath.Dir(outPath), ".copyright-*")
+	if err != nil {
+		return 0, fmt.Errorf("creating output: %w", err)
+	}
+
+	defer os.Remove(file.Name())
+
+	if err := write(file); err != nil {
+		file.Close()
+
+		return 0, err
+	}
+
+	// The temporary file is only readable by its owner, unlike the outputs it replaces.
+	mode := os.FileMode(outputPerm)
+	if info, err := os.Stat(outPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("writing output: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), outPath); err != nil {
+		return 0, fmt.Errorf("renaming output: %w", err)
+	}
+
+	return size, nil
+}
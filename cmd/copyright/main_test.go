@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errRender = errors.New("render failed")
+
+// TestWriteOutput verifies that outputs are readable by others, keep the mode of the file
+// they replace, and are left untouched by a failed write.
+func TestWriteOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "copyright.pdf")
+
+	write := func(content string) func(out io.Writer) error {
+		return func(out io.Writer) error {
+			_, err := io.WriteString(out, content)
+
+			return err
+		}
+	}
+
+	require.NoError(t, writeOutput(outPath, write("%PDF-1")))
+
+	info, err := os.Stat(outPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(outputPerm), info.Mode().Perm())
+
+	require.NoError(t, os.Chmod(outPath, 0o600))
+	require.NoError(t, writeOutput(outPath, write("%PDF-2")))
+
+	info, err = os.Stat(outPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.ErrorIs(t, writeOutput(outPath, func(io.Writer) error { return errRender }), errRender)
+
+	content, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	require.Equal(t, "%PDF-2", string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary file is left")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"biblebrain-services/config"
+	connection_service "biblebrain-services/service/connection"
+	copyright_service "biblebrain-services/service/copyright"
+	logo_service "biblebrain-services/service/logo"
+)
+
+var errPrefetchFailures = errors.New("some logos could not be prefetched")
+
+// runPrefetch implements the "prefetch" command.
+func runPrefetch(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("prefetch", flag.ContinueOnError)
+	orgs := flags.String("orgs", "", "comma-separated organization IDs whose logos to fetch (default: all)")
+	products := flags.String("products", "", "comma-separated product codes whose organizations' logos to fetch")
+	mode := flags.String("mode", "", "restrict -products to 'audio', 'video' or 'text' filesets (default: all modes)")
+	revalidate := flags.Bool("revalidate", false, "check every logo with its origin, even if cached as fresh")
+	strict := flags.Bool("strict", false, "exit with an error when any logo fails")
+	outPath := flags.String("out", "", "output file (default: stdout)")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	opts := copyright_service.PrefetchOptions{ProductCodes: splitList(*products), Mode: *mode, Revalidate: *revalidate}
+
+	for _, part := range splitList(*orgs) {
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return fmt.Errorf("parsing organization ID %q: %w", part, err)
+		}
+
+		opts.OrganizationIDs = append(opts.OrganizationIDs, uint32(id))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	sqlCon, err := connection_service.GetBibleBrainDB(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer sqlCon.Close()
+
+	logos, err := logo_service.New(ctx, cfg.Copyright)
+	if err != nil {
+		return fmt.Errorf("configuring logo cache: %w", err)
+	}
+
+	mgr := copyright_service.New(sqlCon, cfg.Copyright)
+	mgr.Logos = logos
+
+	report, err := mgr.PrefetchLogos(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("prefetching logos: %w", err)
+	}
+
+	err = writeOutput(*outPath, func(out io.Writer) error {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("writing JSON report: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if *strict && len(report.Failures) > 0 {
+		return fmt.Errorf("%w: %d of %d", errPrefetchFailures, len(report.Failures), report.LogosScanned)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package apierror
+
+import (
+	"net/http"
+
+	"biblebrain-services/cmd/httpserver/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stable, machine-readable error codes. Clients may rely on them; messages may change.
+const (
+	CodeInvalidRequest            = "INVALID_REQUEST"
+	CodeProductsRequired          = "PRODUCTS_REQUIRED"
+	CodeInvalidMode               = "INVALID_MODE"
+	CodeInvalidFormat             = "INVALID_FORMAT"
+	CodeInvalidLayout             = "INVALID_LAYOUT"
+	CodeRequestTooLarge           = "REQUEST_TOO_LARGE"
+	CodeUnauthorized              = "UNAUTHORIZED"
+	CodeProductsNotFound          = "PRODUCTS_NOT_FOUND"
+	CodePageNotFound              = "PAGE_NOT_FOUND"
+	CodeJobNotFound               = "JOB_NOT_FOUND"
+	CodeJobNotReady               = "JOB_NOT_READY"
+	CodeJobFailed                 = "JOB_FAILED"
+	CodeDatabaseConfigMissing     = "DATABASE_CONFIG_MISSING"
+	CodeDatabaseSecretUnavailable = "DATABASE_SECRET_UNAVAILABLE"
+	CodeDatabaseUnreachable       = "DATABASE_UNREACHABLE"
+	CodeServiceUnavailable        = "SERVICE_UNAVAILABLE"
+	CodeDatabaseError             = "DATABASE_ERROR"
+	CodePDFGenerationFailed       = "PDF_GENERATION_FAILED"
+	CodeJobStoreError             = "JOB_STORE_ERROR"
+	CodeInternal                  = "INTERNAL_ERROR"
+)
+
+// Error is the body of every API error response.
+type Error struct {
+	// Status is the HTTP status code of the response.
+	Status    int            `json:"-"`
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"requestId,omitempty"`
+}
+
+// New returns an Error with the given status, code and client-facing message.
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// WithDetail returns a copy of e with key set to value in its details.
+func (e *Error) WithDetail(key string, value any) *Error {
+	out := *e
+	out.Details = make(map[string]any, len(e.Details)+1)
+
+	for k, v := range e.Details {
+		out.Details[k] = v
+	}
+
+	out.Details[key] = value
+
+	return &out
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// Respond writes err as the response body with its status, tagged with the request ID, and
+// aborts the handler chain.
+func Respond(gctx *gin.Context, err *Error) {
+	body := *err
+	body.RequestID = middleware.GetRequestID(gctx)
+
+	gctx.AbortWithStatusJSON(body.Status, body)
+}
+
+// NotFound is the NoRoute handler.
+func NotFound(gctx *gin.Context) {
+	Respond(gctx, New(http.StatusNotFound, CodePageNotFound, "Page not found"))
+}
+
+// Recover is a gin.RecoveryFunc that answers a panicking handler with INTERNAL_ERROR.
+func Recover(gctx *gin.Context, _ any) {
+	Respond(gctx, New(http.StatusInternalServerError, CodeInternal, "An internal error occurred"))
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"biblebrain-services/cmd/httpserver/api/apierror"
+	"biblebrain-services/config"
+	connection_service "biblebrain-services/service/connection"
+	copyright_service "biblebrain-services/service/copyright"
+	secret_service "biblebrain-services/service/secret"
+	util "biblebrain-services/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminKeyHeader carries the API key of the admin endpoints.
+const AdminKeyHeader = "X-Api-Key"
+
+// AdminController serves the admin endpoints, which only answer requests carrying the API
+// key held by the secret KeySecret.
+type AdminController struct {
+	Connections *connection_service.Manager
+	Config      config.Copyright
+	Secrets     secret_service.Provider
+	// KeySecret names the secret holding the API key; when empty, every request is refused.
+	KeySecret string
+}
+
+// NewAdmin returns an AdminController that takes its database handles from conns and
+// resolves its API key from the secret keySecret of secrets.
+func NewAdmin(
+	conns *connection_service.Manager,
+	cfg config.Copyright,
+	secrets secret_service.Provider,
+	keySecret string,
+) *AdminController {
+	return &AdminController{Connections: conns, Config: cfg, Secrets: secrets, KeySecret: keySecret}
+}
+
+// Authorize is the middleware of the admin routes: it aborts requests whose X-Api-Key is
+// not the admin API key, and answers as an unknown route while no key is configured.
+func (ctl *AdminController) Authorize(gctx *gin.Context) {
+	if ctl.KeySecret == "" || ctl.Secrets == nil {
+		apierror.NotFound(gctx)
+
+		return
+	}
+
+	key := gctx.GetHeader(AdminKeyHeader)
+	if key == "" {
+		apierror.Respond(gctx, errUnauthorized)
+
+		return
+	}
+
+	ctx := gctx.Request.Context()
+
+	want, err := ctl.Secrets.GetSecret(ctx, ctl.KeySecret)
+	if err != nil {
+		util.LoggerFrom(ctx).Error("Failed to get admin API key", "error", err)
+		apierror.Respond(gctx, unavailable(gctx, apierror.CodeServiceUnavailable,
+			"The service is temporarily unavailable"))
+
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key), []byte(want)) != 1 {
+		util.LoggerFrom(ctx).Warn("Rejected admin request", "path", gctx.Request.URL.Path)
+		apierror.Respond(gctx, errUnauthorized)
+
+		return
+	}
+
+	gctx.Next()
+}
+
+// PrefetchRequest selects the logos warmed by PrefetchLogos; without filters, every logo.
+type PrefetchRequest struct {
+	Organizations []uint32 `binding:"omitempty" form:"organizationId"`
+	Products      []string `binding:"omitempty" form:"productCode"`
+	Mode          string   `binding:"omitempty" form:"mode"`
+	Revalidate    bool     `binding:"omitempty" form:"revalidate"`
+}
+
+func (p *PrefetchRequest) Validate() error {
+	if p.Mode != "" && p.Mode != ModeAudio && p.Mode != ModeVideo && p.Mode != ModeText {
+		return fmt.Errorf("%w: %q, only 'audio', 'video', or 'text' are supported", ErrInvalidMode, p.Mode)
+	}
+
+	return nil
+}
+
+// POST api/admin/logos/prefetch.
+func (ctl *AdminController) PrefetchLogos(gctx *gin.Context) {
+	var req PrefetchRequest
+	if err := gctx.ShouldBindQuery(&req); err != nil {
+		respondError(gctx, err, errBadRequest)
+
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		respondError(gctx, err, errBadRequest)
+
+		return
+	}
+
+	ctx := requestContext(gctx, req.Products, req.Mode)
+
+	sqlCon, err := ctl.Connections.DB(ctx)
+	if err != nil {
+		respondUnavailable(gctx, err)
+
+		return
+	}
+
+	cser := copyright_service.New(sqlCon, ctl.Config)
+
+	report, err := cser.PrefetchLogos(ctx, copyright_service.PrefetchOptions{
+		OrganizationIDs: req.Organizations,
+		ProductCodes:    req.Products,
+		Mode:            req.Mode,
+		Revalidate:      req.Revalidate,
+	})
+	if err != nil {
+		respondError(gctx, err, errDatabase)
+
+		return
+	}
+
+	gctx.JSON(http.StatusOK, report)
+}
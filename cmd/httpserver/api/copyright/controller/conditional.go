@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validators are the caching headers of a successful copyright response. They are only set on
+// successful responses, so that errors are never cached by clients.
+type validators struct {
+	ETag         string
+	LastModified time.Time
+	CacheControl string
+}
+
+func (v validators) set(gctx *gin.Context) {
+	gctx.Header("ETag", v.ETag)
+	gctx.Header("Cache-Control", v.CacheControl)
+
+	if !v.LastModified.IsZero() {
+		gctx.Header("Last-Modified", v.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since of a GET or HEAD request as RFC
+// 9110 does: If-Modified-Since is ignored when If-None-Match is present, and entity tags are
+// compared weakly.
+func notModified(req *http.Request, valid validators) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for tag := range strings.SplitSeq(ifNoneMatch, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(valid.ETag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if valid.LastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates have a resolution of one second.
+	return !valid.LastModified.Truncate(time.Second).After(since)
+}
+
+// cacheControl returns the Cache-Control value for maxAge. Without a max-age, clients must
+// revalidate with the validators on every use.
+func cacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "no-cache"
+	}
+
+	return "public, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+}
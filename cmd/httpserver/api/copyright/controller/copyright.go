@@ -1,14 +1,19 @@
 package controller
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"log/slog"
 	"net/http"
 
+	"biblebrain-services/cmd/httpserver/api/middleware"
+	"biblebrain-services/config"
+	cache_service "biblebrain-services/service/cache"
 	connection_service "biblebrain-services/service/connection"
 	copyright_service "biblebrain-services/service/copyright"
+	util "biblebrain-services/util"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +21,7 @@ import (
 const (
 	FormatPDF  = "pdf"
 	FormatJSON = "json"
+	FormatCSV  = "csv"
 	ModeAudio  = "audio"
 	ModeVideo  = "video"
 	ModeText   = "text"
@@ -28,9 +34,33 @@ var ErrInvalidMode = errors.New("invalid mode")
 
 var ErrInvalidFormat = errors.New("invalid format")
 
+// Controller serves the copyright endpoints using a shared database pool.
+type Controller struct {
+	Connections *connection_service.Manager
+	Config      config.Copyright
+	// Cache keeps generated PDFs; nil disables caching.
+	Cache cache_service.Backend
+}
+
+// New returns a Controller that takes its database handles from conns and keeps generated
+// PDFs in cache, which may be nil.
+func New(conns *connection_service.Manager, cfg config.Copyright, cache cache_service.Backend) *Controller {
+	return &Controller{Connections: conns, Config: cfg, Cache: cache}
+}
+
+// requestContext adds the product codes and mode to the request logger, so every log line
+// of the request, down to the SQL logger, carries them next to the request ID.
+func requestContext(gctx *gin.Context, products []string, mode string) context.Context {
+	ctx := gctx.Request.Context()
+	ctx = util.WithLogger(ctx, util.LoggerFrom(ctx).With("products", products, "mode", mode))
+	gctx.Request = gctx.Request.WithContext(ctx)
+
+	return ctx
+}
+
 type CopyrightRequest struct {
 	// Add fields as needed for the request
-	Products []string `binding:"required"  form:"productCode"`
+	Products []string `binding:"omitempty" form:"productCode"`
 	Format   string   `binding:"omitempty" form:"format"`
 	Mode     string   `binding:"omitempty" form:"mode"`
 }
@@ -53,75 +83,336 @@ func (c *CopyrightRequest) Validate() error {
 }
 
 // GET api/copyright.
-func Get(gctx *gin.Context) {
+func (ctl *Controller) Get(gctx *gin.Context) {
 	var req CopyrightRequest
 	if err := gctx.ShouldBindQuery(&req); err != nil {
-		gctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request 2 data"})
+		respondError(gctx, err, errBadRequest)
 
 		return
 	}
 	// Validate the request data if needed
 	if err := req.Validate(); err != nil {
-		gctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(gctx, err, errBadRequest)
 
 		return
 	}
 
-	ctx := gctx.Request.Context()
-	sqlCon := connection_service.GetBibleBrainDB(ctx)
+	ctl.render(gctx, req, ctl.Config, copyright_service.Layout{})
+}
+
+// MaxBodyBytes limits the size of a POST api/copyright body.
+const MaxBodyBytes = 1 << 20
+
+// CopyrightBody is the JSON body of POST api/copyright, for product lists too long for a URL.
+type CopyrightBody struct {
+	Products []string `json:"products"`
+	Format   string   `json:"format"`
+	Mode     string   `json:"mode"`
+	// LanguageID selects the language of organization names; zero uses the configured one.
+	LanguageID uint32                   `json:"languageId"`
+	Layout     copyright_service.Layout `json:"layout"`
+}
+
+// POST api/copyright. Same rules and output as GET api/copyright, with the request in a JSON body.
+func (ctl *Controller) Post(gctx *gin.Context) {
+	body, ok := bindBody(gctx)
+	if !ok {
+		return
+	}
 
-	defer sqlCon.Close()
+	cfg := ctl.Config
+	if body.LanguageID != 0 {
+		cfg.LanguageID = body.LanguageID
+	}
+
+	req := CopyrightRequest{Products: body.Products, Format: body.Format, Mode: body.Mode}
+	ctl.render(gctx, req, cfg, body.Layout)
+}
+
+// bindBody reads and validates a CopyrightBody; on failure it writes the error and returns false.
+func bindBody(gctx *gin.Context) (CopyrightBody, bool) {
+	gctx.Request.Body = http.MaxBytesReader(gctx.Writer, gctx.Request.Body, MaxBodyBytes)
+
+	var body CopyrightBody
+	if err := gctx.ShouldBindJSON(&body); err != nil {
+		respondError(gctx, err, errBadRequest)
+
+		return body, false
+	}
+
+	middleware.SetMetricLabels(gctx, body.Format, body.Mode)
 
-	cser := copyright_service.New(sqlCon)
+	req := CopyrightRequest{Products: body.Products, Format: body.Format, Mode: body.Mode}
+	if err := req.Validate(); err != nil {
+		respondError(gctx, err, errBadRequest)
+
+		return body, false
+	}
+
+	if err := body.Layout.Validate(); err != nil {
+		respondError(gctx, err, errBadRequest)
+
+		return body, false
+	}
+
+	return body, true
+}
+
+// render looks up the copyrights of a validated request and writes them in its format.
+// Responses carry an ETag and Last-Modified, and a GET whose validators still match is
+// answered 304 before anything is rendered.
+func (ctl *Controller) render(
+	gctx *gin.Context,
+	req CopyrightRequest,
+	cfg config.Copyright,
+	layout copyright_service.Layout,
+) {
+	ctx := requestContext(gctx, req.Products, req.Mode)
+
+	sqlCon, err := ctl.Connections.DB(ctx)
+	if err != nil {
+		respondUnavailable(gctx, err)
+
+		return
+	}
+
+	cser := copyright_service.New(sqlCon, cfg)
 	packageRequest := copyright_service.Package{
 		Products: req.Products,
 	}
+
+	// The version gives Last-Modified and the PDF cache key.
+	version, err := cser.GetVersion(ctx, packageRequest.Products, req.Mode)
+	if err != nil {
+		respondError(gctx, err, errDatabase)
+
+		return
+	}
+
+	if version.IsEmpty() {
+		respondError(gctx, copyright_service.ErrProductsNotFound, errDatabase)
+
+		return
+	}
+
 	// Create the copyright PDF
 	copyrights, err := cser.GetCopyrightBy(ctx, packageRequest.Products, req.Mode)
 	if err != nil {
-		gctx.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get copyrights: %v", err)})
+		respondError(gctx, err, errDatabase)
 
 		return
 	}
 	// If no copyrights are found, return a 404 error
 	if len(copyrights) == 0 {
-		gctx.JSON(http.StatusNotFound, gin.H{"error": "No copyrights found for the provided products"})
+		respondError(gctx, copyright_service.ErrProductsNotFound, errDatabase)
+
+		return
+	}
+
+	// JSON tells which organizations a PDF shows with a placeholder instead of their logo,
+	// which the ETag must cover.
+	if req.Format == FormatJSON {
+		copyrights = cser.MarkLogoPlaceholders(ctx, copyrights)
+	}
+
+	// Logos replaced under the same URL change the document too.
+	logos := cser.CachedLogoDigests(ctx, copyrights)
+
+	valid := validators{
+		ETag:         copyright_service.ETag(copyrights, logos, req.Format, req.Mode, layout),
+		LastModified: version.UpdatedAt,
+		CacheControl: cacheControl(ctl.Config.Cache.MaxAge),
+	}
+
+	if notModified(gctx.Request, valid) {
+		valid.set(gctx)
+		gctx.Status(http.StatusNotModified)
 
 		return
 	}
 
 	switch req.Format {
 	case FormatPDF:
-		pdf, err := cser.StreamCopyright(ctx, copyrights, req.Mode)
+		// A cached PDF is served without downloading logos or rendering.
+		var cacheKey string
+
+		if ctl.Cache != nil {
+			cacheKey = copyright_service.CacheKey(req.Products, req.Mode, req.Format, cfg.LanguageID, layout,
+				version, logos)
+			if ctl.serveCached(gctx, cacheKey, packageRequest, valid) {
+				return
+			}
+		}
+
+		renderCtx, drawn := copyright_service.WithLogoReport(ctx)
+
+		pdf, err := cser.StreamCopyright(renderCtx, copyrights, req.Mode, layout)
 		if err != nil {
-			slog.Error("Failed to stream copyright PDF", "error", err)
-			gctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(gctx, err, errPDFGeneration)
 
 			return
 		}
 
 		defer pdf.Close()
 
-		// Tell the client it’s a PDF
-		gctx.Header("Content-Type", "application/pdf")
-		// Optionally suggest a filename:
-		gctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, packageRequest.ID()))
+		pdfHeaders(gctx, packageRequest)
+		valid.set(gctx)
+
+		// On a cache miss the PDF is also kept in memory, to be stored once fully sent.
+		var (
+			body   io.Writer = gctx.Writer
+			cached bytes.Buffer
+		)
 
-		if _, err := io.Copy(gctx.Writer, pdf); err != nil {
-			// This is a streaming error, so we return an error response
-			gctx.AbortWithStatusJSON(
-				http.StatusInternalServerError,
-				gin.H{"error": fmt.Sprintf("streaming PDF failed: %v", err)},
-			)
+		if cacheKey != "" {
+			gctx.Header("X-Cache", "MISS")
+
+			body = io.MultiWriter(gctx.Writer, &cached)
+		}
+
+		if _, err := io.Copy(body, pdf); err != nil {
+			// Once PDF bytes were sent the status can no longer change; just cut the response.
+			if gctx.Writer.Written() {
+				util.LoggerFrom(ctx).Error("Failed to stream copyright PDF", "error", err)
+				gctx.Abort()
+
+				return
+			}
+
+			respondError(gctx, err, errPDFGeneration)
 
 			return
 		}
+
+		// A PDF missing logos, such as during an outage of their host, is not kept. Otherwise
+		// it is stored under the logos it was drawn with, which the next request finds cached.
+		if cacheKey != "" && drawn.Complete() {
+			cacheKey = copyright_service.CacheKey(req.Products, req.Mode, req.Format, cfg.LanguageID, layout,
+				version, drawn.Digests())
+			if err := ctl.Cache.Put(ctx, cacheKey, cached.Bytes()); err != nil {
+				util.LoggerFrom(ctx).Warn("Failed to cache copyright PDF", "error", err)
+			}
+		}
 	case FormatJSON:
-		// If the format is JSON, return the copyrights as JSON
+		valid.set(gctx)
 		gctx.JSON(http.StatusOK, copyrights)
 	default:
-		gctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format specified"})
+		respondError(gctx, ErrInvalidFormat, errBadRequest)
 
 		return
 	}
 }
+
+// serveCached serves the PDF stored under key, if any, and reports whether it did.
+func (ctl *Controller) serveCached(
+	gctx *gin.Context,
+	key string,
+	packageRequest copyright_service.Package,
+	valid validators,
+) bool {
+	ctx := gctx.Request.Context()
+
+	pdf, err := ctl.Cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache_service.ErrMiss) {
+			util.LoggerFrom(ctx).Warn("Failed to read cached copyright PDF", "error", err)
+		}
+
+		return false
+	}
+
+	pdfHeaders(gctx, packageRequest)
+	valid.set(gctx)
+	gctx.Header("X-Cache", "HIT")
+	gctx.Data(http.StatusOK, "application/pdf", pdf)
+
+	return true
+}
+
+// pdfHeaders sets the headers of a PDF response.
+func pdfHeaders(gctx *gin.Context, packageRequest copyright_service.Package) {
+	// Tell the client it’s a PDF
+	gctx.Header("Content-Type", "application/pdf")
+	// Optionally suggest a filename:
+	gctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, packageRequest.ID()))
+}
+
+// AuditRequest is the query of GET api/copyright/audit. Products are required, and logos are
+// only checked on request, to bound the work of a request; full scans are left to the CLI.
+type AuditRequest struct {
+	Products   []string `binding:"omitempty" form:"productCode"`
+	Format     string   `binding:"omitempty" form:"format"`
+	Mode       string   `binding:"omitempty" form:"mode"`
+	CheckLogos bool     `binding:"omitempty" form:"checkLogos"`
+}
+
+func (a *AuditRequest) Validate() error {
+	if len(a.Products) == 0 {
+		return ErrProductsRequired
+	}
+
+	if a.Format == "" {
+		a.Format = FormatJSON
+	}
+
+	if a.Format != FormatJSON && a.Format != FormatCSV {
+		return fmt.Errorf("%w: %q, only 'json' or 'csv' is supported", ErrInvalidFormat, a.Format)
+	}
+
+	if a.Mode != "" && a.Mode != ModeAudio && a.Mode != ModeVideo && a.Mode != ModeText {
+		return fmt.Errorf("%w: %q, only 'audio', 'video', or 'text' are supported", ErrInvalidMode, a.Mode)
+	}
+
+	return nil
+}
+
+// GET api/copyright/audit.
+func (ctl *Controller) Audit(gctx *gin.Context) {
+	var req AuditRequest
+	if err := gctx.ShouldBindQuery(&req); err != nil {
+		respondError(gctx, err, errBadRequest)
+
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		respondError(gctx, err, errBadRequest)
+
+		return
+	}
+
+	ctx := requestContext(gctx, req.Products, req.Mode)
+
+	sqlCon, err := ctl.Connections.DB(ctx)
+	if err != nil {
+		respondUnavailable(gctx, err)
+
+		return
+	}
+
+	cser := copyright_service.New(sqlCon, ctl.Config)
+
+	report, err := cser.Audit(ctx, copyright_service.AuditOptions{
+		ProductCodes: req.Products,
+		Mode:         req.Mode,
+		CheckLogos:   req.CheckLogos,
+	})
+	if err != nil {
+		respondError(gctx, err, errDatabase)
+
+		return
+	}
+
+	if req.Format == FormatCSV {
+		gctx.Header("Content-Type", "text/csv")
+		gctx.Header("Content-Disposition", `attachment; filename="copyright-audit.csv"`)
+
+		if err := report.WriteCSV(gctx.Writer); err != nil {
+			util.LoggerFrom(ctx).Error("Failed to write audit CSV", "error", err)
+		}
+
+		return
+	}
+
+	gctx.JSON(http.StatusOK, report)
+}
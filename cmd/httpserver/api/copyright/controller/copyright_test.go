@@ -0,0 +1,240 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"biblebrain-services/cmd/httpserver/api/apierror"
+	"biblebrain-services/cmd/httpserver/api/copyright/controller"
+	"biblebrain-services/cmd/httpserver/api/middleware"
+	"biblebrain-services/config"
+	job_service "biblebrain-services/service/job"
+	secret_service "biblebrain-services/service/secret"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetValidationErrors verifies that invalid requests get the error envelope with a stable
+// code and the request ID, before any database access.
+func TestGetValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(middleware.RequestID())
+	engine.GET("/api/copyright", controller.New(nil, config.Default().Copyright, nil).Get)
+	engine.GET("/api/copyright/audit", controller.New(nil, config.Default().Copyright, nil).Audit)
+	engine.NoRoute(apierror.NotFound)
+
+	tests := []struct {
+		url    string
+		status int
+		code   string
+	}{
+		{"/api/copyright?format=pdf&mode=audio", http.StatusBadRequest, apierror.CodeProductsRequired},
+		{"/api/copyright?productCode=N2ENG/NIV&format=xml&mode=audio", http.StatusBadRequest, apierror.CodeInvalidFormat},
+		{"/api/copyright?productCode=N2ENG/NIV&format=pdf&mode=radio", http.StatusBadRequest, apierror.CodeInvalidMode},
+		{"/api/copyright/audit?checkLogos=true", http.StatusBadRequest, apierror.CodeProductsRequired},
+		{"/api/missing", http.StatusNotFound, apierror.CodePageNotFound},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		req.Header.Set(middleware.RequestIDHeader, "req-42")
+
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		require.Equal(t, tc.status, rec.Code, tc.url)
+
+		var body apierror.Error
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Equal(t, tc.code, body.Code, tc.url)
+		require.NotEmpty(t, body.Message, tc.url)
+		require.Equal(t, "req-42", body.RequestID, tc.url)
+	}
+}
+
+// TestPostValidationErrors verifies that a JSON body is validated with the same rules as the
+// query string, plus the layout and size limits.
+func TestPostValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(middleware.RequestID())
+	engine.POST("/api/copyright", controller.New(nil, config.Default().Copyright, nil).Post)
+
+	tests := []struct {
+		body   string
+		status int
+		code   string
+	}{
+		{`{"format":"pdf","mode":"audio"}`, http.StatusBadRequest, apierror.CodeProductsRequired},
+		{`{"products":["N2ENG/NIV"],"format":"xml","mode":"audio"}`, http.StatusBadRequest, apierror.CodeInvalidFormat},
+		{`{"products":["N2ENG/NIV"],"format":"pdf","mode":"radio"}`, http.StatusBadRequest, apierror.CodeInvalidMode},
+		{
+			`{"products":["N2ENG/NIV"],"format":"pdf","mode":"audio","layout":{"pageSize":"A3"}}`,
+			http.StatusBadRequest, apierror.CodeInvalidLayout,
+		},
+		{
+			`{"products":["N2ENG/NIV"],"format":"pdf","mode":"audio","layout":{"gridSize":6}}`,
+			http.StatusBadRequest, apierror.CodeInvalidLayout,
+		},
+		{`{"products":`, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{
+			`{"products":["` + strings.Repeat("x", controller.MaxBodyBytes) + `"]}`,
+			http.StatusRequestEntityTooLarge, apierror.CodeRequestTooLarge,
+		},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/copyright", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		require.Equal(t, tc.status, rec.Code, tc.code)
+
+		var body apierror.Error
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Equal(t, tc.code, body.Code)
+	}
+}
+
+// dispatcherFunc adapts a function to job_service.Dispatcher.
+type dispatcherFunc func(ctx context.Context, id string) error
+
+func (f dispatcherFunc) Dispatch(ctx context.Context, id string) error {
+	return f(ctx, id)
+}
+
+// TestJobLifecycle verifies job creation, status polling and result download with fs stores.
+func TestJobLifecycle(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	jobs := &job_service.FSStore{Dir: dir}
+	results := &job_service.FSResultStore{Dir: dir}
+
+	var dispatched string
+
+	jobController := controller.NewJobs(jobs, results, dispatcherFunc(func(_ context.Context, id string) error {
+		dispatched = id
+
+		return nil
+	}))
+
+	engine := gin.New()
+	engine.Use(middleware.RequestID())
+	engine.POST("/api/copyright/jobs", jobController.Create)
+	engine.GET("/api/copyright/jobs/:id", jobController.Get)
+	engine.GET("/api/copyright/jobs/:id/result", jobController.Result)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/api/copyright/jobs", `{"products":["N2ENG/NIV"],"format":"pdf","mode":"radio"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(http.MethodPost, "/api/copyright/jobs", `{"products":["N2ENG/NIV"],"format":"pdf","mode":"audio"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var job job_service.Job
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+	require.Equal(t, job.ID, dispatched)
+	require.Equal(t, job_service.StatusQueued, job.Status)
+	require.Equal(t, "/api/copyright/jobs/"+job.ID, rec.Header().Get("Location"))
+
+	rec = serve(http.MethodGet, "/api/copyright/jobs/"+job.ID+"/result", "")
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	var apiErr apierror.Error
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+	require.Equal(t, apierror.CodeJobNotReady, apiErr.Code)
+
+	require.NoError(t, results.Put(t.Context(), job.ID, strings.NewReader("%PDF"), job_service.ContentTypePDF))
+
+	job.Status = job_service.StatusSucceeded
+	job.ContentType = job_service.ContentTypePDF
+	job.Size = 4
+	require.NoError(t, jobs.Update(t.Context(), &job))
+
+	rec = serve(http.MethodGet, "/api/copyright/jobs/"+job.ID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"status":"succeeded"`)
+
+	rec = serve(http.MethodGet, "/api/copyright/jobs/"+job.ID+"/result", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, job_service.ContentTypePDF, rec.Header().Get("Content-Type"))
+	require.Equal(t, "%PDF", rec.Body.String())
+
+	rec = serve(http.MethodGet, "/api/copyright/jobs/"+job_service.NewID(), "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+	require.Equal(t, apierror.CodeJobNotFound, apiErr.Code)
+}
+
+// TestAdminAuthorization verifies that admin endpoints refuse requests without the API key,
+// and do not exist while no key is configured.
+func TestAdminAuthorization(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	secrets := filepath.Join(t.TempDir(), "secrets.env")
+	require.NoError(t, os.WriteFile(secrets, []byte("ADMIN_API_KEY=s3cret\n"), 0o600))
+
+	engine := gin.New()
+	engine.Use(middleware.RequestID())
+
+	for path, keySecret := range map[string]string{"/api/admin": "ADMIN_API_KEY", "/api/disabled": ""} {
+		admin := controller.NewAdmin(nil, config.Default().Copyright, secret_service.FileProvider{Path: secrets}, keySecret)
+		engine.Group(path, admin.Authorize).POST("/logos/prefetch", admin.PrefetchLogos)
+	}
+
+	tests := []struct {
+		url    string
+		key    string
+		status int
+		code   string
+	}{
+		{"/api/admin/logos/prefetch", "", http.StatusUnauthorized, apierror.CodeUnauthorized},
+		{"/api/admin/logos/prefetch", "guess", http.StatusUnauthorized, apierror.CodeUnauthorized},
+		{"/api/disabled/logos/prefetch", "s3cret", http.StatusNotFound, apierror.CodePageNotFound},
+		// With the key, the request reaches validation, before any database access.
+		{"/api/admin/logos/prefetch?mode=radio", "s3cret", http.StatusBadRequest, apierror.CodeInvalidMode},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, tc.url, nil)
+		if tc.key != "" {
+			req.Header.Set(controller.AdminKeyHeader, tc.key)
+		}
+
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		require.Equal(t, tc.status, rec.Code, tc.url)
+
+		var body apierror.Error
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Equal(t, tc.code, body.Code, tc.url)
+	}
+}
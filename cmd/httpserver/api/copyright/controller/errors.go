@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"biblebrain-services/cmd/httpserver/api/apierror"
+	connection_service "biblebrain-services/service/connection"
+	copyright_service "biblebrain-services/service/copyright"
+	job_service "biblebrain-services/service/job"
+	util "biblebrain-services/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetryAfterSeconds is the retry hint sent with 503 responses caused by transient failures.
+const RetryAfterSeconds = 5
+
+// respondError maps err to an API error and writes it. Validation errors are returned with
+// their message since it only describes the client's own input; unrecognized errors become
+// fallback, so internal details such as SQL errors never reach the client.
+func respondError(gctx *gin.Context, err error, fallback *apierror.Error) {
+	apiErr := fallback
+
+	var tooLarge *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, ErrProductsRequired):
+		apiErr = apierror.New(http.StatusBadRequest, apierror.CodeProductsRequired,
+			"At least one productCode is required")
+	case errors.Is(err, ErrInvalidMode):
+		apiErr = apierror.New(http.StatusBadRequest, apierror.CodeInvalidMode, err.Error()).
+			WithDetail("parameter", "mode")
+	case errors.Is(err, ErrInvalidFormat):
+		apiErr = apierror.New(http.StatusBadRequest, apierror.CodeInvalidFormat, err.Error()).
+			WithDetail("parameter", "format")
+	case errors.Is(err, copyright_service.ErrInvalidLayout):
+		apiErr = apierror.New(http.StatusBadRequest, apierror.CodeInvalidLayout, err.Error()).
+			WithDetail("parameter", "layout")
+	case errors.As(err, &tooLarge):
+		apiErr = apierror.New(http.StatusRequestEntityTooLarge, apierror.CodeRequestTooLarge,
+			"The request body is too large").
+			WithDetail("limitBytes", tooLarge.Limit)
+	case errors.Is(err, copyright_service.ErrProductsNotFound):
+		apiErr = apierror.New(http.StatusNotFound, apierror.CodeProductsNotFound,
+			"No copyrights found for the provided products")
+	case errors.Is(err, job_service.ErrNotFound):
+		apiErr = apierror.New(http.StatusNotFound, apierror.CodeJobNotFound,
+			"The job does not exist or has expired")
+	}
+
+	if apiErr.Status >= http.StatusInternalServerError {
+		util.LoggerFrom(gctx.Request.Context()).Error("Request failed", "code", apiErr.Code, "error", err)
+	}
+
+	apierror.Respond(gctx, apiErr)
+}
+
+// respondUnavailable writes a 503 error for a failure to obtain a database connection.
+// Transient failures carry a Retry-After header and a retry hint in the details.
+func respondUnavailable(gctx *gin.Context, err error) {
+	util.LoggerFrom(gctx.Request.Context()).Error("Failed to get database connection", "error", err)
+
+	var apiErr *apierror.Error
+
+	switch {
+	case errors.Is(err, connection_service.ErrConfigMissing):
+		apiErr = apierror.New(http.StatusServiceUnavailable, apierror.CodeDatabaseConfigMissing,
+			"The database connection is not configured").
+			WithDetail("retryable", false)
+	case errors.Is(err, connection_service.ErrSecretUnavailable):
+		apiErr = unavailable(gctx, apierror.CodeDatabaseSecretUnavailable,
+			"The database credentials could not be retrieved")
+	case errors.Is(err, connection_service.ErrDBUnreachable):
+		apiErr = unavailable(gctx, apierror.CodeDatabaseUnreachable, "The database is unreachable")
+	default:
+		apiErr = unavailable(gctx, apierror.CodeServiceUnavailable, "The service is temporarily unavailable")
+	}
+
+	apierror.Respond(gctx, apiErr)
+}
+
+// unavailable returns a retryable 503 error and sets the Retry-After header.
+func unavailable(gctx *gin.Context, code, message string) *apierror.Error {
+	gctx.Header("Retry-After", strconv.Itoa(RetryAfterSeconds))
+
+	return apierror.New(http.StatusServiceUnavailable, code, message).
+		WithDetail("retryable", true).
+		WithDetail("retryAfterSeconds", RetryAfterSeconds)
+}
+
+// Fallback errors for failures whose cause must not be shown to clients.
+var (
+	errDatabase = apierror.New(http.StatusInternalServerError, apierror.CodeDatabaseError,
+		"The copyright data could not be retrieved")
+	errPDFGeneration = apierror.New(http.StatusInternalServerError, apierror.CodePDFGenerationFailed,
+		"The copyright PDF could not be generated")
+	errJobStore = apierror.New(http.StatusInternalServerError, apierror.CodeJobStoreError,
+		"The job could not be stored or read")
+	errBadRequest = apierror.New(http.StatusBadRequest, apierror.CodeInvalidRequest,
+		"The request parameters are invalid")
+	errUnauthorized = apierror.New(http.StatusUnauthorized, apierror.CodeUnauthorized,
+		"A valid API key is required")
+)
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"biblebrain-services/cmd/httpserver/api/apierror"
+	job_service "biblebrain-services/service/job"
+	util "biblebrain-services/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResultURLTTL is how long a presigned result URL stays valid.
+const ResultURLTTL = 15 * time.Minute
+
+// JobController serves the asynchronous copyright job endpoints.
+type JobController struct {
+	Jobs       job_service.Store
+	Results    job_service.ResultStore
+	Dispatcher job_service.Dispatcher
+}
+
+// NewJobs returns a JobController that keeps jobs in jobs and results, and starts them with dispatcher.
+func NewJobs(jobs job_service.Store, results job_service.ResultStore, dispatcher job_service.Dispatcher) *JobController {
+	return &JobController{Jobs: jobs, Results: results, Dispatcher: dispatcher}
+}
+
+// POST api/copyright/jobs. Takes the body of POST api/copyright and answers 202 with the job.
+func (ctl *JobController) Create(gctx *gin.Context) {
+	body, ok := bindBody(gctx)
+	if !ok {
+		return
+	}
+
+	ctx := requestContext(gctx, body.Products, body.Mode)
+
+	now := time.Now().UTC()
+	job := &job_service.Job{
+		ID:        job_service.NewID(),
+		Status:    job_service.StatusQueued,
+		Request:   job_service.Request(body),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := ctl.Jobs.Create(ctx, job); err != nil {
+		respondError(gctx, err, errJobStore)
+
+		return
+	}
+
+	if err := ctl.Dispatcher.Dispatch(ctx, job.ID); err != nil {
+		util.LoggerFrom(ctx).Error("Failed to dispatch job", "job_id", job.ID, "error", err)
+
+		job.Status = job_service.StatusFailed
+		job.Error = "The job could not be started"
+		job.UpdatedAt = time.Now().UTC()
+
+		if err := ctl.Jobs.Update(ctx, job); err != nil {
+			util.LoggerFrom(ctx).Error("Failed to record job failure", "job_id", job.ID, "error", err)
+		}
+
+		apierror.Respond(gctx, unavailable(gctx, apierror.CodeServiceUnavailable, "The job could not be started"))
+
+		return
+	}
+
+	util.LoggerFrom(ctx).Info("Job queued", "job_id", job.ID)
+
+	gctx.Header("Location", "/api/copyright/jobs/"+job.ID)
+	gctx.JSON(http.StatusAccepted, job)
+}
+
+// GET api/copyright/jobs/:id.
+func (ctl *JobController) Get(gctx *gin.Context) {
+	job, ok := ctl.job(gctx)
+	if !ok {
+		return
+	}
+
+	gctx.JSON(http.StatusOK, job)
+}
+
+// GET api/copyright/jobs/:id/result. Redirects to the document when the result store can
+// presign it, and streams it otherwise.
+func (ctl *JobController) Result(gctx *gin.Context) {
+	job, ok := ctl.job(gctx)
+	if !ok {
+		return
+	}
+
+	switch job.Status {
+	case job_service.StatusSucceeded:
+	case job_service.StatusFailed:
+		apierror.Respond(gctx, apierror.New(http.StatusConflict, apierror.CodeJobFailed, job.Error).
+			WithDetail("status", job.Status))
+
+		return
+	default:
+		gctx.Header("Retry-After", strconv.Itoa(RetryAfterSeconds))
+		apierror.Respond(gctx, apierror.New(http.StatusConflict, apierror.CodeJobNotReady, "The job has not finished yet").
+			WithDetail("status", job.Status).
+			WithDetail("retryAfterSeconds", RetryAfterSeconds))
+
+		return
+	}
+
+	ctx := gctx.Request.Context()
+
+	if presigner, ok := ctl.Results.(job_service.Presigner); ok {
+		url, err := presigner.PresignGet(ctx, job.ID, ResultURLTTL)
+		if err != nil {
+			respondError(gctx, err, errJobStore)
+
+			return
+		}
+
+		gctx.Redirect(http.StatusSeeOther, url)
+
+		return
+	}
+
+	result, err := ctl.Results.Open(ctx, job.ID)
+	if err != nil {
+		respondError(gctx, err, errJobStore)
+
+		return
+	}
+	defer result.Close()
+
+	extension := "json"
+	if job.ContentType == job_service.ContentTypePDF {
+		extension = "pdf"
+	}
+
+	gctx.Header("Content-Type", job.ContentType)
+	gctx.Header("Content-Length", strconv.FormatInt(job.Size, 10))
+	gctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="copyright-%s.%s"`, job.ID, extension))
+
+	if _, err := io.Copy(gctx.Writer, result); err != nil {
+		util.LoggerFrom(ctx).Error("Failed to stream job result", "job_id", job.ID, "error", err)
+		gctx.Abort()
+	}
+}
+
+// job loads the job named in the path; on failure it writes the error and returns false.
+func (ctl *JobController) job(gctx *gin.Context) (*job_service.Job, bool) {
+	id := gctx.Param("id")
+	if !job_service.ValidID(id) {
+		respondError(gctx, job_service.ErrNotFound, errJobStore)
+
+		return nil, false
+	}
+
+	job, err := ctl.Jobs.Get(gctx.Request.Context(), id)
+	if err != nil {
+		respondError(gctx, err, errJobStore)
+
+		return nil, false
+	}
+
+	return job, true
+}
@@ -2,51 +2,262 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"biblebrain-services/cmd/httpserver/api/apierror"
 	copyright_controller "biblebrain-services/cmd/httpserver/api/copyright/controller"
+	"biblebrain-services/cmd/httpserver/api/middleware"
 	status_controller "biblebrain-services/cmd/httpserver/api/status/controller"
+	"biblebrain-services/config"
+	cache_service "biblebrain-services/service/cache"
+	connection_service "biblebrain-services/service/connection"
+	health_service "biblebrain-services/service/health"
+	job_service "biblebrain-services/service/job"
+	logo_service "biblebrain-services/service/logo"
+	metrics_service "biblebrain-services/service/metrics"
+	secret_service "biblebrain-services/service/secret"
+	tracing_service "biblebrain-services/service/tracing"
 	util "biblebrain-services/util"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	ginadapter "github.com/awslabs/aws-lambda-go-api-proxy/gin"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func setupRouter() *ginadapter.GinLambda {
-	// Set up logger
-	logger := util.Logger(os.Getenv("LOG_LEVEL"))
-	slog.SetDefault(logger)
+// setupRouter builds the Gin engine. When metricsHandler is not nil it is served on /metrics.
+func setupRouter(
+	cfg *config.Config,
+	conns *connection_service.Manager,
+	secrets secret_service.Provider,
+	pdfCache cache_service.Backend,
+	jobController *copyright_controller.JobController,
+	metricsHandler http.Handler,
+) *gin.Engine {
 	slog.Info("Initializing router")
 
+	copyrightController := copyright_controller.New(conns, cfg.Copyright, pdfCache)
+	adminController := copyright_controller.NewAdmin(conns, cfg.Copyright, secrets, cfg.Admin.KeySecret)
+	statusController := status_controller.New(
+		health_service.NewChecker(cfg.Health.Timeout, readinessChecks(cfg, conns, secrets)...),
+	)
+
 	// Build Gin engine and routes
-	gengine := gin.Default()
+	gengine := gin.New()
+	gengine.Use(gin.Logger(), gin.CustomRecovery(apierror.Recover))
+	gengine.Use(otelgin.Middleware(cfg.Tracing.ServiceName), middleware.RequestID(), middleware.Metrics())
+
+	if metricsHandler != nil {
+		gengine.GET("/metrics", gin.WrapH(metricsHandler))
+	}
+
 	api := gengine.Group("/api")
 	{
-		api.GET("/status", status_controller.Get)
-		api.GET("/copyright", copyright_controller.Get)
+		api.GET("/status", statusController.Get)
+		api.GET("/health/live", statusController.Live)
+		api.GET("/health/ready", statusController.Ready)
+		api.GET("/copyright", copyrightController.Get)
+		api.POST("/copyright", copyrightController.Post)
+		api.GET("/copyright/audit", copyrightController.Audit)
+		api.POST("/copyright/jobs", jobController.Create)
+		api.GET("/copyright/jobs/:id", jobController.Get)
+		api.GET("/copyright/jobs/:id/result", jobController.Result)
 	}
 
-	gengine.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"code":    "PAGE_NOT_FOUND",
-			"message": "Page not found",
-		})
-	})
+	admin := api.Group("/admin", adminController.Authorize)
+	{
+		admin.POST("/logos/prefetch", adminController.PrefetchLogos)
+	}
+
+	gengine.NoRoute(apierror.NotFound)
+
+	return gengine
+}
+
+// readinessChecks returns the checks of the dependencies and of the local directories the
+// configuration writes to.
+func readinessChecks(
+	cfg *config.Config,
+	conns *connection_service.Manager,
+	secrets secret_service.Provider,
+) []health_service.Check {
+	checks := []health_service.Check{
+		health_service.DatabaseCheck(conns),
+		health_service.SecretCheck(secrets, cfg.DSNSecretName()),
+		health_service.WritableDirCheck("logo_dir", cfg.Copyright.Logos.Dir),
+	}
+
+	if cfg.Copyright.Cache.Backend == config.CacheBackendDisk {
+		checks = append(checks, health_service.WritableDirCheck("cache_dir", cfg.Copyright.Cache.Dir))
+	}
+
+	if cfg.Jobs.Store == config.JobStoreFS || cfg.Jobs.ResultStore == config.ResultStoreFS {
+		checks = append(checks, health_service.WritableDirCheck("jobs_dir", cfg.Jobs.Dir))
+	}
 
-	return ginadapter.New(gengine)
+	return append(checks, health_service.HTTPCheck("logo_host", cfg.Health.LogoProbeURL))
 }
 
 func main() {
-	// Create the adapter exactly once, in main()
-	ginLambda := setupRouter()
+	// Load and validate the whole configuration before anything else starts.
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	version, commit := util.BuildInfo()
+
+	// Set up logger
+	redactor, err := cfg.Log.Redactor()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	logger := util.NewLogger(cfg.Log.Level, cfg.Log.Format, redactor)
+	slog.SetDefault(logger)
+	slog.Info("Starting biblebrain-services", "version", version, "commit", commit)
+
+	// Secrets are cached for the whole process, so SSM is not hit on every reconnect.
+	secrets, err := secret_service.NewCached(context.Background(), cfg.Secrets)
+	if err != nil {
+		slog.Error("Invalid secret provider configuration", "error", err)
+		os.Exit(1)
+	}
+
+	tracer, err := tracing_service.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		slog.Error("Invalid tracing configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// The pool lives for the whole Lambda container or server process.
+	conns := connection_service.NewBibleBrainManager(cfg, secrets)
+
+	// Lambda publishes metrics as EMF log lines; the standalone server exposes /metrics.
+	var metricsHandler http.Handler
+
+	if cfg.Server.Mode == config.ServerModeHTTP {
+		recorder := metrics_service.NewPrometheus(cfg.Metrics.Namespace)
+		metrics_service.SetDefault(recorder)
+		metricsHandler = recorder.Handler()
+	} else {
+		metrics_service.SetDefault(metrics_service.NewEMF(os.Stdout, cfg.Metrics.Namespace))
+	}
+
+	pdfCache, err := cache_service.New(context.Background(), cfg.Copyright.Cache)
+	if err != nil {
+		slog.Error("Invalid PDF cache configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logos, err := logo_service.New(context.Background(), cfg.Copyright)
+	if err != nil {
+		slog.Error("Invalid logo cache configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logo_service.SetDefault(logos)
+
+	jobs, err := job_service.NewStore(context.Background(), cfg.Jobs)
+	if err != nil {
+		slog.Error("Invalid job store configuration", "error", err)
+		os.Exit(1)
+	}
+
+	results, err := job_service.NewResultStore(context.Background(), cfg.Jobs)
+	if err != nil {
+		slog.Error("Invalid job result store configuration", "error", err)
+		os.Exit(1)
+	}
+
+	worker := job_service.NewWorker(jobs, results, conns, cfg)
+
+	// Jobs run in their own Lambda invocation when a worker function is configured, and in
+	// process otherwise.
+	var (
+		dispatcher job_service.Dispatcher
+		local      *job_service.LocalDispatcher
+	)
+
+	if cfg.Jobs.WorkerFunction != "" {
+		dispatcher, err = job_service.NewLambdaDispatcher(context.Background(), cfg.Jobs.WorkerFunction)
+		if err != nil {
+			slog.Error("Invalid job dispatcher configuration", "error", err)
+			os.Exit(1)
+		}
+	} else {
+		if cfg.Server.Mode == config.ServerModeLambda {
+			slog.Warn("No JOBS_WORKER_FUNCTION configured; jobs run in process and may be frozen with the container")
+		}
+
+		local = job_service.NewLocalDispatcher(worker.Run, cfg.Jobs.Concurrency)
+		dispatcher = local
+	}
+
+	jobController := copyright_controller.NewJobs(jobs, results, dispatcher)
+
+	// Build the engine exactly once, in main()
+	gengine := setupRouter(cfg, conns, secrets, pdfCache, jobController, metricsHandler)
+
+	if cfg.Server.Mode == config.ServerModeHTTP {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+
+		err = runHTTPServer(ctx, gengine, httpServerOptionsFrom(cfg.Server))
+		if local != nil {
+			local.Close()
+		}
+
+		if closeErr := conns.Close(); closeErr != nil {
+			slog.Warn("Failed to close database pool", "error", closeErr)
+		}
+
+		if shutdownErr := tracer.Shutdown(context.WithoutCancel(ctx)); shutdownErr != nil {
+			slog.Warn("Failed to flush traces", "error", shutdownErr)
+		}
+
+		if err != nil {
+			slog.Error("HTTP server failed", "error", err)
+			stop()
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	ginLambda := ginadapter.New(gengine)
+
+	// Start Lambda with a closure that captures our adapter. The same function also runs jobs
+	// when it is invoked with a job event by the Lambda dispatcher.
+	lambda.Start(func(ctx context.Context, payload json.RawMessage) (any, error) {
+		// The container may be frozen as soon as we return, so export this invocation's spans now.
+		defer func() {
+			if flushErr := tracer.ForceFlush(ctx); flushErr != nil {
+				slog.Warn("Failed to flush traces", "error", flushErr)
+			}
+		}()
+
+		var event job_service.Event
+		if err := json.Unmarshal(payload, &event); err == nil && event.JobID != "" {
+			return nil, worker.Run(ctx, event.JobID)
+		}
+
+		var req events.APIGatewayProxyRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return nil, fmt.Errorf("decoding API Gateway event: %w", err)
+		}
 
-	// Start Lambda with a closure that captures our adapter
-	lambda.Start(func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-		slog.Info("Handling request", "path", req.Path, "params", req.PathParameters)
+		slog.Debug("Received API Gateway event",
+			"request_id", req.RequestContext.RequestID, "path", req.Path, "params", req.PathParameters)
 
 		return ginLambda.ProxyWithContext(ctx, req)
 	})
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"time"
+
+	metrics_service "biblebrain-services/service/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Label values accepted for the format and mode query parameters. Anything else is recorded
+// as "other" so that arbitrary client input cannot create new metric series.
+var (
+	metricFormats = []string{"", "pdf", "json", "csv"}
+	metricModes   = []string{"", "audio", "video", "text"}
+)
+
+// Context keys of the labels set by SetMetricLabels.
+const (
+	metricFormatKey = "metrics.format"
+	metricModeKey   = "metrics.mode"
+)
+
+// SetMetricLabels sets the format and mode labels of a request that does not carry them in the
+// query string, such as a JSON body.
+func SetMetricLabels(gctx *gin.Context, format, mode string) {
+	gctx.Set(metricFormatKey, format)
+	gctx.Set(metricModeKey, mode)
+}
+
+// Metrics records the count and latency of every request, by route, format and mode, with
+// the process-wide metrics recorder.
+func Metrics() gin.HandlerFunc {
+	return func(gctx *gin.Context) {
+		start := time.Now()
+
+		gctx.Next()
+
+		route := gctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics_service.Default().ObserveRequest(metrics_service.Request{
+			Route:  route,
+			Format: metricLabel(labelValue(gctx, metricFormatKey, "format"), metricFormats),
+			Mode:   metricLabel(labelValue(gctx, metricModeKey, "mode"), metricModes),
+			Status: gctx.Writer.Status(),
+		}, time.Since(start))
+	}
+}
+
+func metricLabel(value string, allowed []string) string {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return value
+		}
+	}
+
+	return "other"
+}
+
+// labelValue returns the label set by SetMetricLabels under key, or else the query parameter.
+func labelValue(gctx *gin.Context, key, param string) string {
+	if value, ok := gctx.Get(key); ok {
+		if label, ok := value.(string); ok {
+			return label
+		}
+	}
+
+	return gctx.Query(param)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"log/slog"
+
+	util "biblebrain-services/util"
+
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request correlation ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey stores the request ID in the gin context; see GetRequestID.
+const requestIDKey = "requestID"
+
+// maxRequestIDLength bounds client supplied IDs so they cannot flood the logs.
+const maxRequestIDLength = 128
+
+// RequestID assigns every request a correlation ID and stores a logger carrying it in the
+// request context (see util.LoggerFrom). The ID is taken from the X-Request-ID header, then
+// from the API Gateway request context, and is generated otherwise. It is echoed in the
+// X-Request-ID response header. When the request is traced, the logger also carries the
+// trace ID, so RequestID must run after the tracing middleware.
+func RequestID() gin.HandlerFunc {
+	return func(gctx *gin.Context) {
+		ctx := gctx.Request.Context()
+
+		requestID := gctx.GetHeader(RequestIDHeader)
+		if len(requestID) > maxRequestIDLength {
+			requestID = ""
+		}
+
+		if requestID == "" {
+			if apiGwCtx, ok := core.GetAPIGatewayContextFromContext(ctx); ok {
+				requestID = apiGwCtx.RequestID
+			}
+		}
+
+		if requestID == "" {
+			requestID = rand.Text()
+		}
+
+		logger := slog.Default().With("request_id", requestID)
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+			logger = logger.With("trace_id", spanCtx.TraceID().String())
+		}
+		gctx.Request = gctx.Request.WithContext(util.WithLogger(ctx, logger))
+		gctx.Set(requestIDKey, requestID)
+		gctx.Header(RequestIDHeader, requestID)
+
+		logger.Info("Handling request", "method", gctx.Request.Method, "path", gctx.Request.URL.Path)
+
+		gctx.Next()
+
+		logger.Info("Request completed", "status", gctx.Writer.Status())
+	}
+}
+
+// GetRequestID returns the ID assigned by RequestID, or "" when the middleware did not run.
+func GetRequestID(gctx *gin.Context) string {
+	return gctx.GetString(requestIDKey)
+}
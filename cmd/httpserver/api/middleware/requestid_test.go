@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"biblebrain-services/cmd/httpserver/api/middleware"
+	util "biblebrain-services/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestID verifies that a client ID is propagated and a missing one is generated.
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(middleware.RequestID())
+	engine.GET("/", func(gctx *gin.Context) {
+		// The handler sees a request-scoped logger, not the process default.
+		if util.LoggerFrom(gctx.Request.Context()) == slog.Default() {
+			gctx.Status(http.StatusInternalServerError)
+
+			return
+		}
+		gctx.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "client-id")
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "client-id", rec.Header().Get(middleware.RequestIDHeader))
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NotEmpty(t, rec.Header().Get(middleware.RequestIDHeader))
+	require.NotEqual(t, "client-id", rec.Header().Get(middleware.RequestIDHeader))
+}
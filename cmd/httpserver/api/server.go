@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"biblebrain-services/config"
+)
+
+type httpServerOptions struct {
+	Addr            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+}
+
+// httpServerOptionsFrom returns the standalone server settings of the server configuration.
+func httpServerOptionsFrom(cfg config.Server) httpServerOptions {
+	return httpServerOptions{
+		Addr:            net.JoinHostPort("", strconv.Itoa(cfg.Port)),
+		ReadTimeout:     cfg.ReadTimeout,
+		WriteTimeout:    cfg.WriteTimeout,
+		IdleTimeout:     cfg.IdleTimeout,
+		ShutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+// runHTTPServer serves handler until ctx is cancelled, then drains in-flight requests
+// for up to opts.ShutdownTimeout before returning.
+func runHTTPServer(ctx context.Context, handler http.Handler, opts httpServerOptions) error {
+	srv := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           handler,
+		ReadTimeout:       opts.ReadTimeout,
+		ReadHeaderTimeout: opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		slog.Info("HTTP server listening", "addr", opts.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("listening on %s: %w", opts.Addr, err)
+		}
+
+		return nil
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down HTTP server", "timeout", opts.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opts.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down HTTP server: %w", err)
+	}
+
+	return nil
+}
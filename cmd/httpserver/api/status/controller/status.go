@@ -2,10 +2,47 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
+
+	health_service "biblebrain-services/service/health"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Get(gctx *gin.Context) {
+// Controller serves the status and health endpoints.
+type Controller struct {
+	Checker *health_service.Checker
+}
+
+// New returns a Controller whose readiness checks are run by checker.
+func New(checker *health_service.Checker) *Controller {
+	return &Controller{Checker: checker}
+}
+
+// GET api/status. With deep=true the readiness checks are run, as for api/health/ready.
+func (ctl *Controller) Get(gctx *gin.Context) {
+	if deep, _ := strconv.ParseBool(gctx.Query("deep")); deep {
+		ctl.Ready(gctx)
+
+		return
+	}
+
 	gctx.JSON(http.StatusOK, "AWS Lambda ops biblebrain-service is running!")
 }
+
+// GET api/health/live. Reports the build without touching any dependency.
+func (ctl *Controller) Live(gctx *gin.Context) {
+	gctx.JSON(http.StatusOK, health_service.Live())
+}
+
+// GET api/health/ready. Runs every dependency check; any failure yields 503.
+func (ctl *Controller) Ready(gctx *gin.Context) {
+	report := ctl.Checker.Run(gctx.Request.Context())
+
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	gctx.JSON(status, report)
+}
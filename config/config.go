@@ -0,0 +1,698 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	util "biblebrain-services/util"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environments.
+const (
+	EnvironmentLocal = "local"
+	EnvironmentDev   = "dev"
+	EnvironmentProd  = "prod"
+)
+
+// Server modes.
+const (
+	ServerModeLambda = "lambda"
+	ServerModeHTTP   = "http"
+)
+
+// Secret providers.
+const (
+	SecretProviderEnv            = "env"
+	SecretProviderFile           = "file"
+	SecretProviderSSM            = "ssm"
+	SecretProviderSecretsManager = "secretsmanager"
+)
+
+// Tracing exporters.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// Job stores.
+const (
+	JobStoreFS       = "fs"
+	JobStoreDynamoDB = "dynamodb"
+)
+
+// Job result stores.
+const (
+	ResultStoreFS = "fs"
+	ResultStoreS3 = "s3"
+)
+
+// PDF cache backends.
+const (
+	CacheBackendNone   = "none"
+	CacheBackendMemory = "memory"
+	CacheBackendDisk   = "disk"
+	CacheBackendS3     = "s3"
+)
+
+// Logo cache stores.
+const (
+	LogoStoreDisk = "disk"
+	LogoStoreS3   = "s3"
+)
+
+// Bounds of the logo DPI: below, logos print blurry; above, they only grow the PDF.
+const (
+	minLogoDPI = 72
+	maxLogoDPI = 1200
+)
+
+// EnglishLanguageID is the BibleBrain language ID of English, used for organization names.
+const EnglishLanguageID = 6414
+
+// metricsNamespacePattern keeps the namespace valid both for CloudWatch and as a Prometheus prefix.
+var metricsNamespacePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// ErrInvalid is wrapped by the error returned from Load and Validate when any field is invalid.
+var ErrInvalid = errors.New("invalid configuration")
+
+// Config is the complete, validated application configuration.
+type Config struct {
+	Environment string    `yaml:"environment"`
+	Log         Log       `yaml:"log"`
+	Server      Server    `yaml:"server"`
+	Database    Database  `yaml:"database"`
+	Secrets     Secrets   `yaml:"secrets"`
+	Copyright   Copyright `yaml:"copyright"`
+	Metrics     Metrics   `yaml:"metrics"`
+	Tracing     Tracing   `yaml:"tracing"`
+	Health      Health    `yaml:"health"`
+	Jobs        Jobs      `yaml:"jobs"`
+	Admin       Admin     `yaml:"admin"`
+}
+
+// Log configures logging and log redaction.
+type Log struct {
+	Level                string   `yaml:"level"`
+	Format               string   `yaml:"format"`
+	AllowedAttrs         []string `yaml:"allowedAttrs"`
+	SensitiveArgPatterns []string `yaml:"sensitiveArgPatterns"`
+	MaxInListArgs        int      `yaml:"maxInListArgs"`
+}
+
+// Server configures the standalone HTTP server.
+type Server struct {
+	Mode            string        `yaml:"mode"`
+	Port            int           `yaml:"port"`
+	ReadTimeout     time.Duration `yaml:"readTimeout"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout"`
+	IdleTimeout     time.Duration `yaml:"idleTimeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+}
+
+// Database configures the BibleBrain connection pool and where its DSN is stored.
+type Database struct {
+	MaxOpenConns        int           `yaml:"maxOpenConns"`
+	MaxIdleConns        int           `yaml:"maxIdleConns"`
+	ConnMaxLifetime     time.Duration `yaml:"connMaxLifetime"`
+	ConnMaxIdleTime     time.Duration `yaml:"connMaxIdleTime"`
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval"`
+	// DSNSSMParameter is the SSM parameter holding the DSN for the ssm provider.
+	DSNSSMParameter string `yaml:"dsnSsmParameter"`
+	// DSNSecretID is the Secrets Manager secret holding the DSN for the secretsmanager provider.
+	DSNSecretID string `yaml:"dsnSecretId"`
+}
+
+// Secrets configures the secret provider.
+type Secrets struct {
+	Provider string        `yaml:"provider"`
+	File     string        `yaml:"file"`
+	CacheTTL time.Duration `yaml:"cacheTtl"`
+}
+
+// Copyright configures copyright PDF generation.
+type Copyright struct {
+	MaxConcurrentDownloads int           `yaml:"maxConcurrentDownloads"`
+	DownloadTimeout        time.Duration `yaml:"downloadTimeout"`
+	LanguageID             uint32        `yaml:"languageId"`
+	Cache                  Cache         `yaml:"cache"`
+	Logos                  Logos         `yaml:"logos"`
+}
+
+// Cache configures the cache of generated PDFs.
+type Cache struct {
+	// Backend is none, memory (an in-process LRU), disk, or s3 for any S3-compatible store.
+	Backend string `yaml:"backend"`
+	// MaxBytes bounds the memory backend.
+	MaxBytes int    `yaml:"maxBytes"`
+	Dir      string `yaml:"dir"`
+	Bucket   string `yaml:"bucket"`
+	Prefix   string `yaml:"prefix"`
+	// Endpoint overrides the S3 endpoint, for S3-compatible stores such as MinIO.
+	Endpoint string `yaml:"endpoint"`
+	// MaxAge is the Cache-Control max-age of copyright responses.
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
+// Logos configures the cache of organization logos.
+type Logos struct {
+	// Store is disk, or s3 for any S3-compatible store shared by all containers.
+	Store string `yaml:"store"`
+	// Dir holds the disk store, and the local copies of logos read by the PDF renderer.
+	Dir      string `yaml:"dir"`
+	Bucket   string `yaml:"bucket"`
+	Prefix   string `yaml:"prefix"`
+	Endpoint string `yaml:"endpoint"`
+	// TTL is how long a cached logo is used before it is revalidated with its origin.
+	TTL time.Duration `yaml:"ttl"`
+	// Mirror, when set, is a local directory read instead of downloading http(s) logos: the
+	// logo at https://host/a.png is Mirror/host/a.png.
+	Mirror string `yaml:"mirror"`
+	// Retries is how many times a failed HTTP download is retried, after RetryBackoff and
+	// twice as long before each next retry.
+	Retries      int           `yaml:"retries"`
+	RetryBackoff time.Duration `yaml:"retryBackoff"`
+	// MaxBytes bounds the size of a single logo.
+	MaxBytes int `yaml:"maxBytes"`
+	// Budget bounds the bytes downloaded for the logos of one PDF; 0 disables it.
+	Budget int `yaml:"budget"`
+	// AllowedSchemes are the logo URL schemes fetched, among http, https and s3.
+	AllowedSchemes []string `yaml:"allowedSchemes"`
+	// AllowedHosts restricts the hosts, or S3 buckets, logos are fetched from: "example.com"
+	// allows that host and "*.example.com" its subdomains. Empty allows any public host.
+	AllowedHosts []string `yaml:"allowedHosts"`
+	// DPI is the print resolution logos are rendered at: SVGs are rasterized at the size they
+	// are drawn at this resolution, and raster logos scaled down to it.
+	DPI int `yaml:"dpi"`
+	// MaxPixels bounds the width times height of raster logos, checked before they are
+	// decoded, since a small file can declare an image that takes gigabytes once decoded.
+	MaxPixels int `yaml:"maxPixels"`
+}
+
+// Metrics configures metrics: CloudWatch EMF in lambda mode, a Prometheus /metrics
+// endpoint in http mode.
+type Metrics struct {
+	// Namespace is the CloudWatch namespace, and in snake_case the Prometheus metric prefix.
+	Namespace string `yaml:"namespace"`
+}
+
+// Tracing configures OpenTelemetry tracing.
+type Tracing struct {
+	// Exporter is none, stdout for local runs, or otlp.
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the OTLP/HTTP traces URL; when empty the OTEL_EXPORTER_OTLP_* variables apply.
+	Endpoint    string  `yaml:"endpoint"`
+	ServiceName string  `yaml:"serviceName"`
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// Health configures the readiness checks.
+type Health struct {
+	// Timeout bounds each individual check.
+	Timeout time.Duration `yaml:"timeout"`
+	// LogoProbeURL is a sample logo whose host must be reachable; empty skips the check.
+	LogoProbeURL string `yaml:"logoProbeUrl"`
+}
+
+// Jobs configures asynchronous copyright jobs.
+type Jobs struct {
+	// Store keeps job records: fs, or dynamodb for Lambda.
+	Store string `yaml:"store"`
+	// ResultStore keeps the generated documents: fs, or s3 for Lambda.
+	ResultStore string `yaml:"resultStore"`
+	// Dir is the root directory of the fs stores.
+	Dir string `yaml:"dir"`
+	// Table is the DynamoDB table of the dynamodb store, keyed by the string attribute "id".
+	Table  string `yaml:"table"`
+	Bucket string `yaml:"bucket"`
+	Prefix string `yaml:"prefix"`
+	// WorkerFunction is the Lambda function invoked asynchronously to run a job; when empty,
+	// jobs run in process, which only suits the http server mode.
+	WorkerFunction string `yaml:"workerFunction"`
+	// Concurrency bounds the jobs run at once in process.
+	Concurrency int `yaml:"concurrency"`
+	// Timeout bounds a single job.
+	Timeout time.Duration `yaml:"timeout"`
+	// TTL is how long job records are kept by the dynamodb store; zero keeps them forever.
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// Admin configures the admin endpoints.
+type Admin struct {
+	// KeySecret names the secret, in the configured secret provider, holding the API key the
+	// admin endpoints require; when empty, the admin endpoints are disabled.
+	KeySecret string `yaml:"keySecret"`
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	return &Config{
+		Environment: "",
+		Log: Log{
+			Level:         util.LogLevelError,
+			Format:        util.LogFormatText,
+			MaxInListArgs: util.DefaultMaxInListArgs,
+		},
+		Server: Server{
+			Mode:            ServerModeLambda,
+			Port:            8080,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Database: Database{
+			MaxOpenConns:        4,
+			MaxIdleConns:        2,
+			ConnMaxLifetime:     5 * time.Minute,
+			ConnMaxIdleTime:     time.Minute,
+			HealthCheckInterval: 30 * time.Second,
+		},
+		Secrets: Secrets{
+			CacheTTL: 15 * time.Minute,
+		},
+		Copyright: Copyright{
+			MaxConcurrentDownloads: 8,
+			DownloadTimeout:        10 * time.Second,
+			LanguageID:             EnglishLanguageID,
+			Cache: Cache{
+				Backend:  CacheBackendMemory,
+				MaxBytes: 16 << 20,
+				Dir:      "/tmp/copyright-cache",
+				Prefix:   "pdf/",
+				MaxAge:   time.Hour,
+			},
+			Logos: Logos{
+				Store:          LogoStoreDisk,
+				Dir:            "/tmp/copyright-logos",
+				Prefix:         "logos/",
+				TTL:            24 * time.Hour,
+				Retries:        2,
+				RetryBackoff:   200 * time.Millisecond,
+				MaxBytes:       5 << 20,
+				Budget:         64 << 20,
+				AllowedSchemes: []string{"https", "http", "s3"},
+				DPI:            300,
+				MaxPixels:      8_000_000,
+			},
+		},
+		Metrics: Metrics{
+			Namespace: "BibleBrainServices",
+		},
+		Tracing: Tracing{
+			Exporter:    TracingExporterNone,
+			ServiceName: "biblebrain-services",
+			SampleRatio: 1,
+		},
+		Health: Health{
+			Timeout: 3 * time.Second,
+		},
+		Jobs: Jobs{
+			Store:       JobStoreFS,
+			ResultStore: ResultStoreFS,
+			Dir:         "/tmp/copyright-jobs",
+			Prefix:      "jobs/",
+			Concurrency: 2,
+			Timeout:     10 * time.Minute,
+			TTL:         7 * 24 * time.Hour,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the optional YAML file named by CONFIG_FILE,
+// and environment variables, in increasing order of precedence, then validates it.
+func Load() (*Config, error) {
+	return LoadFrom(os.Getenv("CONFIG_FILE"), os.LookupEnv)
+}
+
+// LoadFrom is Load with an explicit YAML path (empty for none) and environment lookup.
+func LoadFrom(path string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file %q: %w", path, err)
+		}
+
+		if err := yaml.Unmarshal(content, cfg); err != nil {
+			return nil, fmt.Errorf("parsing config file %q: %w", path, err)
+		}
+	}
+
+	problems := cfg.applyEnv(lookupEnv)
+
+	if cfg.Secrets.Provider == "" {
+		cfg.Secrets.Provider = SecretProviderSSM
+		if cfg.Environment == EnvironmentLocal {
+			cfg.Secrets.Provider = SecretProviderEnv
+		}
+	}
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+// Validate reports every invalid field of cfg.
+func (c *Config) Validate() error {
+	if problems := c.validate(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// DSNSecretName returns the name under which the DSN is stored for the configured provider:
+// the SSM parameter, the Secrets Manager secret ID, or the BIBLEBRAIN_DSN key for the
+// environment and file providers.
+func (c *Config) DSNSecretName() string {
+	switch c.Secrets.Provider {
+	case SecretProviderSSM:
+		return c.Database.DSNSSMParameter
+	case SecretProviderSecretsManager:
+		return c.Database.DSNSecretID
+	default:
+		return "BIBLEBRAIN_DSN"
+	}
+}
+
+// Redactor builds the log redactor described by the Log section.
+func (l Log) Redactor() (*util.Redactor, error) {
+	patterns := util.DefaultSensitiveArgPatterns()
+
+	for _, expr := range l.SensitiveArgPatterns {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("compiling sensitive arg pattern %q: %w", expr, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return util.NewRedactor(append(util.DefaultLogAttrs(), l.AllowedAttrs...), patterns, l.MaxInListArgs), nil
+}
+
+// FieldError describes one invalid configuration field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (f FieldError) String() string {
+	return f.Field + ": " + f.Message
+}
+
+// ValidationError lists every invalid field found while loading the configuration.
+type ValidationError struct {
+	Problems []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		lines = append(lines, "  - "+problem.String())
+	}
+
+	return fmt.Sprintf("%s (%d problems):\n%s", ErrInvalid, len(e.Problems), strings.Join(lines, "\n"))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalid
+}
+
+// applyEnv overrides fields from environment variables and returns parse failures.
+func (c *Config) applyEnv(lookupEnv func(string) (string, bool)) []FieldError {
+	var problems []FieldError
+
+	str := func(name string, target *string) {
+		if value, ok := lookupEnv(name); ok && value != "" {
+			*target = value
+		}
+	}
+	list := func(name string, target *[]string) {
+		if value, ok := lookupEnv(name); ok && value != "" {
+			for item := range strings.SplitSeq(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					*target = append(*target, item)
+				}
+			}
+		}
+	}
+	integer := func(name string, target *int) {
+		if value, ok := lookupEnv(name); ok && value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				problems = append(problems, FieldError{name, fmt.Sprintf("%q is not an integer", value)})
+
+				return
+			}
+			*target = parsed
+		}
+	}
+	duration := func(name string, target *time.Duration) {
+		if value, ok := lookupEnv(name); ok && value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				problems = append(problems, FieldError{name, fmt.Sprintf("%q is not a duration", value)})
+
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	str("environment", &c.Environment)
+
+	str("LOG_LEVEL", &c.Log.Level)
+	str("LOG_FORMAT", &c.Log.Format)
+	list("LOG_ALLOWED_ATTRS", &c.Log.AllowedAttrs)
+	list("LOG_SENSITIVE_ARG_PATTERNS", &c.Log.SensitiveArgPatterns)
+	integer("LOG_MAX_IN_LIST_ARGS", &c.Log.MaxInListArgs)
+
+	str("SERVER_MODE", &c.Server.Mode)
+	integer("PORT", &c.Server.Port)
+	duration("HTTP_READ_TIMEOUT", &c.Server.ReadTimeout)
+	duration("HTTP_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	duration("HTTP_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+	duration("HTTP_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+
+	integer("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	integer("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	duration("DB_CONN_MAX_IDLE_TIME", &c.Database.ConnMaxIdleTime)
+	duration("DB_HEALTH_CHECK_INTERVAL", &c.Database.HealthCheckInterval)
+	str("BIBLEBRAIN_DSN_SSM_ID", &c.Database.DSNSSMParameter)
+	str("BIBLEBRAIN_DSN_SECRET_ID", &c.Database.DSNSecretID)
+
+	str("SECRET_PROVIDER", &c.Secrets.Provider)
+	str("SECRET_FILE", &c.Secrets.File)
+	duration("SECRET_CACHE_TTL", &c.Secrets.CacheTTL)
+	str("ADMIN_KEY_SECRET", &c.Admin.KeySecret)
+
+	integer("COPYRIGHT_MAX_CONCURRENT_DOWNLOADS", &c.Copyright.MaxConcurrentDownloads)
+	duration("COPYRIGHT_DOWNLOAD_TIMEOUT", &c.Copyright.DownloadTimeout)
+
+	if value, ok := lookupEnv("COPYRIGHT_LANGUAGE_ID"); ok && value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			problems = append(problems, FieldError{"COPYRIGHT_LANGUAGE_ID", fmt.Sprintf("%q is not a language ID", value)})
+		} else {
+			c.Copyright.LanguageID = uint32(parsed)
+		}
+	}
+
+	str("COPYRIGHT_CACHE_BACKEND", &c.Copyright.Cache.Backend)
+	integer("COPYRIGHT_CACHE_MAX_BYTES", &c.Copyright.Cache.MaxBytes)
+	str("COPYRIGHT_CACHE_DIR", &c.Copyright.Cache.Dir)
+	str("COPYRIGHT_CACHE_BUCKET", &c.Copyright.Cache.Bucket)
+	str("COPYRIGHT_CACHE_PREFIX", &c.Copyright.Cache.Prefix)
+	str("COPYRIGHT_CACHE_ENDPOINT", &c.Copyright.Cache.Endpoint)
+	duration("COPYRIGHT_CACHE_MAX_AGE", &c.Copyright.Cache.MaxAge)
+
+	str("COPYRIGHT_LOGOS_STORE", &c.Copyright.Logos.Store)
+	str("COPYRIGHT_LOGOS_DIR", &c.Copyright.Logos.Dir)
+	str("COPYRIGHT_LOGOS_BUCKET", &c.Copyright.Logos.Bucket)
+	str("COPYRIGHT_LOGOS_PREFIX", &c.Copyright.Logos.Prefix)
+	str("COPYRIGHT_LOGOS_ENDPOINT", &c.Copyright.Logos.Endpoint)
+	duration("COPYRIGHT_LOGOS_TTL", &c.Copyright.Logos.TTL)
+	str("COPYRIGHT_LOGOS_MIRROR", &c.Copyright.Logos.Mirror)
+	integer("COPYRIGHT_LOGOS_RETRIES", &c.Copyright.Logos.Retries)
+	duration("COPYRIGHT_LOGOS_RETRY_BACKOFF", &c.Copyright.Logos.RetryBackoff)
+	integer("COPYRIGHT_LOGOS_MAX_BYTES", &c.Copyright.Logos.MaxBytes)
+	integer("COPYRIGHT_LOGOS_BUDGET", &c.Copyright.Logos.Budget)
+
+	// Unlike the log lists, the schemes replace the defaults.
+	if value, ok := lookupEnv("COPYRIGHT_LOGOS_ALLOWED_SCHEMES"); ok && value != "" {
+		c.Copyright.Logos.AllowedSchemes = nil
+	}
+
+	list("COPYRIGHT_LOGOS_ALLOWED_SCHEMES", &c.Copyright.Logos.AllowedSchemes)
+	list("COPYRIGHT_LOGOS_ALLOWED_HOSTS", &c.Copyright.Logos.AllowedHosts)
+	integer("COPYRIGHT_LOGOS_DPI", &c.Copyright.Logos.DPI)
+	integer("COPYRIGHT_LOGOS_MAX_PIXELS", &c.Copyright.Logos.MaxPixels)
+
+	str("METRICS_NAMESPACE", &c.Metrics.Namespace)
+
+	str("TRACING_EXPORTER", &c.Tracing.Exporter)
+	str("TRACING_ENDPOINT", &c.Tracing.Endpoint)
+	str("OTEL_SERVICE_NAME", &c.Tracing.ServiceName)
+
+	if value, ok := lookupEnv("TRACING_SAMPLE_RATIO"); ok && value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			problems = append(problems, FieldError{"TRACING_SAMPLE_RATIO", fmt.Sprintf("%q is not a number", value)})
+		} else {
+			c.Tracing.SampleRatio = parsed
+		}
+	}
+
+	duration("HEALTH_CHECK_TIMEOUT", &c.Health.Timeout)
+	str("HEALTH_LOGO_PROBE_URL", &c.Health.LogoProbeURL)
+
+	str("JOBS_STORE", &c.Jobs.Store)
+	str("JOBS_RESULT_STORE", &c.Jobs.ResultStore)
+	str("JOBS_DIR", &c.Jobs.Dir)
+	str("JOBS_TABLE", &c.Jobs.Table)
+	str("JOBS_BUCKET", &c.Jobs.Bucket)
+	str("JOBS_PREFIX", &c.Jobs.Prefix)
+	str("JOBS_WORKER_FUNCTION", &c.Jobs.WorkerFunction)
+	integer("JOBS_CONCURRENCY", &c.Jobs.Concurrency)
+	duration("JOBS_TIMEOUT", &c.Jobs.Timeout)
+	duration("JOBS_TTL", &c.Jobs.TTL)
+
+	return problems
+}
+
+// validate checks every field independently so that all problems are reported at once.
+func (c *Config) validate() []FieldError {
+	var problems []FieldError
+
+	check := func(ok bool, field, message string) {
+		if !ok {
+			problems = append(problems, FieldError{field, message})
+		}
+	}
+	oneOf := func(value string, allowed ...string) bool {
+		for _, candidate := range allowed {
+			if value == candidate {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	check(c.Environment == "" || oneOf(c.Environment, EnvironmentLocal, EnvironmentDev, EnvironmentProd),
+		"environment", fmt.Sprintf("%q must be one of local, dev, prod", c.Environment))
+
+	check(oneOf(c.Log.Level, "", util.LogLevelError, util.LogLevelInfo, util.LogLevelDebug),
+		"log.level", fmt.Sprintf("%q must be one of Error, Info, Debug", c.Log.Level))
+	check(oneOf(c.Log.Format, util.LogFormatText, util.LogFormatJSON),
+		"log.format", fmt.Sprintf("%q must be one of text, json", c.Log.Format))
+	check(c.Log.MaxInListArgs >= 0, "log.maxInListArgs", "must not be negative")
+
+	for _, expr := range c.Log.SensitiveArgPatterns {
+		_, err := regexp.Compile(expr)
+		check(err == nil, "log.sensitiveArgPatterns", fmt.Sprintf("%q is not a valid regular expression", expr))
+	}
+
+	check(oneOf(c.Server.Mode, ServerModeLambda, ServerModeHTTP),
+		"server.mode", fmt.Sprintf("%q must be one of lambda, http", c.Server.Mode))
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", fmt.Sprintf("%d is not a valid port", c.Server.Port))
+	check(c.Server.ReadTimeout > 0, "server.readTimeout", "must be positive")
+	check(c.Server.WriteTimeout > 0, "server.writeTimeout", "must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idleTimeout", "must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdownTimeout", "must be positive")
+
+	check(c.Database.MaxOpenConns > 0, "database.maxOpenConns", "must be positive")
+	check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.maxIdleConns", "must be between 0 and database.maxOpenConns")
+	check(c.Database.ConnMaxLifetime >= 0, "database.connMaxLifetime", "must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.connMaxIdleTime", "must not be negative")
+	check(c.Database.HealthCheckInterval >= 0, "database.healthCheckInterval", "must not be negative")
+
+	switch c.Secrets.Provider {
+	case SecretProviderEnv:
+	case SecretProviderFile:
+		check(c.Secrets.File != "", "secrets.file", "is required for the file provider")
+	case SecretProviderSSM:
+		check(c.Database.DSNSSMParameter != "", "database.dsnSsmParameter", "is required for the ssm provider")
+	case SecretProviderSecretsManager:
+		check(c.Database.DSNSecretID != "", "database.dsnSecretId", "is required for the secretsmanager provider")
+	default:
+		check(false, "secrets.provider",
+			fmt.Sprintf("%q must be one of env, file, ssm, secretsmanager", c.Secrets.Provider))
+	}
+	check(c.Secrets.CacheTTL >= 0, "secrets.cacheTtl", "must not be negative")
+
+	check(c.Copyright.MaxConcurrentDownloads > 0, "copyright.maxConcurrentDownloads", "must be positive")
+	check(c.Copyright.DownloadTimeout > 0, "copyright.downloadTimeout", "must be positive")
+	check(c.Copyright.LanguageID > 0, "copyright.languageId", "must be positive")
+	check(oneOf(c.Copyright.Cache.Backend, CacheBackendNone, CacheBackendMemory, CacheBackendDisk, CacheBackendS3),
+		"copyright.cache.backend", fmt.Sprintf("%q must be one of none, memory, disk, s3", c.Copyright.Cache.Backend))
+	check(c.Copyright.Cache.Backend != CacheBackendMemory || c.Copyright.Cache.MaxBytes > 0,
+		"copyright.cache.maxBytes", "must be positive for the memory backend")
+	check(c.Copyright.Cache.Backend != CacheBackendDisk || filepath.IsAbs(c.Copyright.Cache.Dir),
+		"copyright.cache.dir", fmt.Sprintf("%q must be an absolute path", c.Copyright.Cache.Dir))
+	check(c.Copyright.Cache.Backend != CacheBackendS3 || c.Copyright.Cache.Bucket != "",
+		"copyright.cache.bucket", "is required for the s3 backend")
+	check(c.Copyright.Cache.MaxAge >= 0, "copyright.cache.maxAge", "must not be negative")
+	check(oneOf(c.Copyright.Logos.Store, LogoStoreDisk, LogoStoreS3),
+		"copyright.logos.store", fmt.Sprintf("%q must be one of disk, s3", c.Copyright.Logos.Store))
+	check(filepath.IsAbs(c.Copyright.Logos.Dir), "copyright.logos.dir",
+		fmt.Sprintf("%q must be an absolute path", c.Copyright.Logos.Dir))
+	check(c.Copyright.Logos.Store != LogoStoreS3 || c.Copyright.Logos.Bucket != "",
+		"copyright.logos.bucket", "is required for the s3 store")
+	check(c.Copyright.Logos.TTL >= 0, "copyright.logos.ttl", "must not be negative")
+	check(c.Copyright.Logos.Mirror == "" || filepath.IsAbs(c.Copyright.Logos.Mirror), "copyright.logos.mirror",
+		fmt.Sprintf("%q must be an absolute path", c.Copyright.Logos.Mirror))
+	check(c.Copyright.Logos.Retries >= 0, "copyright.logos.retries", "must not be negative")
+	check(c.Copyright.Logos.RetryBackoff >= 0, "copyright.logos.retryBackoff", "must not be negative")
+	check(c.Copyright.Logos.MaxBytes > 0, "copyright.logos.maxBytes", "must be positive")
+	check(c.Copyright.Logos.Budget >= 0, "copyright.logos.budget", "must not be negative")
+	check(len(c.Copyright.Logos.AllowedSchemes) > 0, "copyright.logos.allowedSchemes", "must not be empty")
+
+	for _, scheme := range c.Copyright.Logos.AllowedSchemes {
+		check(oneOf(scheme, "http", "https", "s3"), "copyright.logos.allowedSchemes",
+			fmt.Sprintf("%q must be one of http, https, s3", scheme))
+	}
+
+	check(c.Copyright.Logos.DPI >= minLogoDPI && c.Copyright.Logos.DPI <= maxLogoDPI, "copyright.logos.dpi",
+		fmt.Sprintf("%d must be between %d and %d", c.Copyright.Logos.DPI, minLogoDPI, maxLogoDPI))
+	check(c.Copyright.Logos.MaxPixels > 0, "copyright.logos.maxPixels", "must be positive")
+
+	check(metricsNamespacePattern.MatchString(c.Metrics.Namespace), "metrics.namespace",
+		fmt.Sprintf("%q must start with a letter and contain only letters and digits", c.Metrics.Namespace))
+
+	check(oneOf(c.Tracing.Exporter, TracingExporterNone, TracingExporterStdout, TracingExporterOTLP),
+		"tracing.exporter", fmt.Sprintf("%q must be one of none, stdout, otlp", c.Tracing.Exporter))
+	check(c.Tracing.ServiceName != "", "tracing.serviceName", "is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio", "must be between 0 and 1")
+
+	check(c.Health.Timeout > 0, "health.timeout", "must be positive")
+
+	check(oneOf(c.Jobs.Store, JobStoreFS, JobStoreDynamoDB),
+		"jobs.store", fmt.Sprintf("%q must be one of fs, dynamodb", c.Jobs.Store))
+	check(oneOf(c.Jobs.ResultStore, ResultStoreFS, ResultStoreS3),
+		"jobs.resultStore", fmt.Sprintf("%q must be one of fs, s3", c.Jobs.ResultStore))
+	check(c.Jobs.Store != JobStoreDynamoDB || c.Jobs.Table != "", "jobs.table", "is required for the dynamodb store")
+	check(c.Jobs.ResultStore != ResultStoreS3 || c.Jobs.Bucket != "", "jobs.bucket", "is required for the s3 result store")
+	check(c.Jobs.Store != JobStoreFS && c.Jobs.ResultStore != ResultStoreFS || filepath.IsAbs(c.Jobs.Dir),
+		"jobs.dir", fmt.Sprintf("%q must be an absolute path", c.Jobs.Dir))
+	check(c.Jobs.Concurrency > 0, "jobs.concurrency", "must be positive")
+	check(c.Jobs.Timeout > 0, "jobs.timeout", "must be positive")
+	check(c.Jobs.TTL >= 0, "jobs.ttl", "must not be negative")
+
+	return problems
+}
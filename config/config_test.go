@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"biblebrain-services/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+// envMap returns a lookupEnv function backed by vars.
+func envMap(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+
+		return value, ok
+	}
+}
+
+// TestLoadDefaults verifies that a local environment with no overrides is valid.
+func TestLoadDefaults(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := config.LoadFrom("", envMap(map[string]string{"environment": config.EnvironmentLocal}))
+	require.NoError(t, err)
+	require.Equal(t, config.SecretProviderEnv, cfg.Secrets.Provider)
+	require.Equal(t, config.ServerModeLambda, cfg.Server.Mode)
+	require.Equal(t, uint32(config.EnglishLanguageID), cfg.Copyright.LanguageID)
+	require.Equal(t, "BIBLEBRAIN_DSN", cfg.DSNSecretName())
+}
+
+// TestLoadFilePrecedence verifies that the YAML file overrides defaults and env overrides the file.
+func TestLoadFilePrecedence(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "environment: dev\n" +
+		"server:\n  mode: http\n  port: 9000\n" +
+		"database:\n  dsnSsmParameter: /dev/rds/DSN\n" +
+		"copyright:\n  languageId: 17045\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	cfg, err := config.LoadFrom(path, envMap(map[string]string{
+		"PORT":                       "9100",
+		"COPYRIGHT_DOWNLOAD_TIMEOUT": "3s",
+	}))
+	require.NoError(t, err)
+	require.Equal(t, config.EnvironmentDev, cfg.Environment)
+	require.Equal(t, config.ServerModeHTTP, cfg.Server.Mode)
+	require.Equal(t, 9100, cfg.Server.Port)
+	require.Equal(t, config.SecretProviderSSM, cfg.Secrets.Provider)
+	require.Equal(t, "/dev/rds/DSN", cfg.DSNSecretName())
+	require.Equal(t, uint32(17045), cfg.Copyright.LanguageID)
+	require.Equal(t, 3*time.Second, cfg.Copyright.DownloadTimeout)
+}
+
+// TestLoadReportsAllProblems verifies that every invalid field is reported in one error.
+func TestLoadReportsAllProblems(t *testing.T) {
+	t.Parallel()
+
+	_, err := config.LoadFrom("", envMap(map[string]string{
+		"environment":                        "staging",
+		"PORT":                               "http",
+		"SERVER_MODE":                        "grpc",
+		"COPYRIGHT_MAX_CONCURRENT_DOWNLOADS": "0",
+		"SECRET_PROVIDER":                    "vault",
+	}))
+	require.ErrorIs(t, err, config.ErrInvalid)
+
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	fields := make([]string, 0, len(validationErr.Problems))
+	for _, problem := range validationErr.Problems {
+		fields = append(fields, problem.Field)
+	}
+
+	require.ElementsMatch(t, []string{
+		"PORT", "environment", "server.mode", "secrets.provider", "copyright.maxConcurrentDownloads",
+	}, fields)
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"biblebrain-services/config"
+)
+
+// Backend stores generated PDFs by key. Keys are hex digests, such as those returned by
+// copyright.CacheKey, and a key always maps to the same content, so entries never need to be
+// invalidated, only evicted.
+type Backend interface {
+	// Get returns the document stored under key, or ErrMiss.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, value []byte) error
+}
+
+// ErrMiss indicates that no document is stored under the key.
+var ErrMiss = errors.New("cache miss")
+
+// ErrInvalidKey indicates a key that is not a hex digest.
+var ErrInvalidKey = errors.New("invalid cache key")
+
+// ErrUnknownBackend indicates that the configuration names an unsupported backend.
+var ErrUnknownBackend = errors.New("unknown cache backend")
+
+// keyPattern keeps keys safe to use as file names and object keys.
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{16,128}$`)
+
+func validKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+
+	return nil
+}
+
+// New builds the backend for cfg.Backend. It returns nil when caching is disabled.
+func New(ctx context.Context, cfg config.Cache) (Backend, error) {
+	switch cfg.Backend {
+	case config.CacheBackendNone:
+		return nil, nil
+	case config.CacheBackendMemory:
+		return NewLRU(int64(cfg.MaxBytes)), nil
+	case config.CacheBackendDisk:
+		return &Disk{Dir: cfg.Dir}, nil
+	case config.CacheBackendS3:
+		return NewS3(ctx, cfg.Bucket, cfg.Prefix, cfg.Endpoint)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBackend, cfg.Backend)
+	}
+}
@@ -0,0 +1,114 @@
+package cache_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"biblebrain-services/config"
+	cache_service "biblebrain-services/service/cache"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLRU verifies that the least recently used documents are evicted first and that
+// documents larger than the cache are not kept.
+func TestLRU(t *testing.T) {
+	t.Parallel()
+
+	keyA, keyB, keyC := strings.Repeat("a", 64), strings.Repeat("b", 64), strings.Repeat("c", 64)
+
+	lru := cache_service.NewLRU(10)
+	require.NoError(t, lru.Put(t.Context(), keyA, []byte("aaaa")))
+	require.NoError(t, lru.Put(t.Context(), keyB, []byte("bbbb")))
+
+	_, err := lru.Get(t.Context(), keyA)
+	require.NoError(t, err)
+
+	require.NoError(t, lru.Put(t.Context(), keyC, []byte("cccc")))
+	require.Equal(t, 2, lru.Len())
+
+	_, err = lru.Get(t.Context(), keyB)
+	require.ErrorIs(t, err, cache_service.ErrMiss)
+
+	value, err := lru.Get(t.Context(), keyA)
+	require.NoError(t, err)
+	require.Equal(t, "aaaa", string(value))
+
+	require.NoError(t, lru.Put(t.Context(), keyB, []byte("too large for the cache")))
+	_, err = lru.Get(t.Context(), keyB)
+	require.ErrorIs(t, err, cache_service.ErrMiss)
+}
+
+// TestDisk verifies the disk round trip and that keys cannot escape the directory.
+func TestDisk(t *testing.T) {
+	t.Parallel()
+
+	disk := &cache_service.Disk{Dir: t.TempDir()}
+	key := strings.Repeat("0f", 32)
+
+	_, err := disk.Get(t.Context(), key)
+	require.ErrorIs(t, err, cache_service.ErrMiss)
+
+	require.NoError(t, disk.Put(t.Context(), key, []byte("%PDF")))
+
+	value, err := disk.Get(t.Context(), key)
+	require.NoError(t, err)
+	require.Equal(t, "%PDF", string(value))
+
+	require.ErrorIs(t, disk.Put(t.Context(), "../../etc/passwd", nil), cache_service.ErrInvalidKey)
+}
+
+// TestNew verifies that the none backend disables caching.
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default().Copyright.Cache
+
+	backend, err := cache_service.New(t.Context(), cfg)
+	require.NoError(t, err)
+	require.IsType(t, &cache_service.LRU{}, backend)
+
+	cfg.Backend = config.CacheBackendNone
+	backend, err = cache_service.New(t.Context(), cfg)
+	require.NoError(t, err)
+	require.Nil(t, backend)
+}
+
+// TestS3Miss verifies that a missing document is a miss, including the 403 S3 answers to
+// callers without s3:ListBucket, and that other failures are errors.
+func TestS3Miss(t *testing.T) {
+	t.Parallel()
+
+	status := map[string]int{
+		"missing": http.StatusNotFound,
+		"denied":  http.StatusForbidden,
+		"down":    http.StatusBadGateway,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status[strings.Split(r.URL.Path, "/")[1]])
+	}))
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		Region:           "us-west-2",
+		BaseEndpoint:     aws.String(server.URL),
+		UsePathStyle:     true,
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+	key := strings.Repeat("0f", 32)
+
+	for _, bucket := range []string{"missing", "denied"} {
+		_, err := (&cache_service.S3{Client: client, Bucket: bucket}).Get(t.Context(), key)
+		require.ErrorIs(t, err, cache_service.ErrMiss, bucket)
+	}
+
+	_, err := (&cache_service.S3{Client: client, Bucket: "down"}).Get(t.Context(), key)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, cache_service.ErrMiss)
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	dirPerm  = 0o750
+	filePerm = 0o640
+)
+
+// Disk keeps each document as a file in Dir, sharded by the first two characters of its key.
+// Nothing is evicted; Dir is expected to be on temporary storage, such as /tmp of a Lambda
+// container, or cleaned up externally.
+type Disk struct {
+	Dir string
+}
+
+// Verify at compile-time that *Disk implements Backend.
+var _ Backend = (*Disk)(nil)
+
+// Get reads the document stored under key.
+func (d *Disk) Get(_ context.Context, key string) ([]byte, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	value, err := os.ReadFile(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrMiss
+	} else if err != nil {
+		return nil, fmt.Errorf("reading cache entry %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// Put writes value under key. The file is written under a temporary name and renamed, so a
+// concurrent Get never reads a partial document.
+func (d *Disk) Put(_ context.Context, key string, value []byte) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(d.path(key))
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing cache entry %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("writing cache entry %s: %w", key, err)
+	}
+
+	if err := tmp.Chmod(filePerm); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("writing cache entry %s: %w", key, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache entry %s: %w", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		return fmt.Errorf("writing cache entry %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (d *Disk) path(key string) string {
+	return filepath.Join(d.Dir, key[:2], key)
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// LRU keeps documents in process memory, evicting the least recently used once their total
+// size exceeds MaxBytes. Documents larger than MaxBytes are not kept at all.
+type LRU struct {
+	maxBytes int64
+	mu       sync.Mutex
+	size     int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// Verify at compile-time that *LRU implements Backend.
+var _ Backend = (*LRU)(nil)
+
+// NewLRU returns an empty LRU holding at most maxBytes of documents.
+func NewLRU(maxBytes int64) *LRU {
+	return &LRU{maxBytes: maxBytes, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get returns the document stored under key and marks it as recently used.
+func (c *LRU) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+
+	c.order.MoveToFront(element)
+
+	entry, _ := element.Value.(*lruEntry)
+
+	return entry.value, nil
+}
+
+// Put stores value under key, evicting older documents as needed.
+func (c *LRU) Put(_ context.Context, key string, value []byte) error {
+	if int64(len(value)) > c.maxBytes {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	c.size += int64(len(value))
+
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Len returns the number of documents held.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRU) remove(element *list.Element) {
+	entry, _ := element.Value.(*lruEntry)
+
+	c.order.Remove(element)
+	delete(c.items, entry.key)
+	c.size -= int64(len(entry.value))
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3 keeps each document as the object Prefix+key in Bucket of S3 or an S3-compatible store.
+// Expiry is left to a lifecycle rule on the bucket.
+type S3 struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+// Verify at compile-time that *S3 implements Backend.
+var _ Backend = (*S3)(nil)
+
+// NewS3 returns an S3 backend using the default AWS configuration. A non-empty endpoint
+// selects an S3-compatible store, addressed with path-style URLs.
+func NewS3(ctx context.Context, bucket, prefix, endpoint string) (*S3, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithDefaultRegion("us-west-2"))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(options *s3.Options) {
+		if endpoint != "" {
+			options.BaseEndpoint = aws.String(endpoint)
+			options.UsePathStyle = true
+		}
+	})
+
+	return &S3{Client: client, Bucket: bucket, Prefix: prefix}, nil
+}
+
+// Get downloads the document stored under key.
+func (c *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	output, err := c.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(c.Prefix + key),
+	})
+	if err != nil {
+		if missing(err) {
+			return nil, ErrMiss
+		}
+
+		return nil, fmt.Errorf("downloading cache entry %s: %w", key, err)
+	}
+	defer output.Body.Close()
+
+	value, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading cache entry %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// missing reports whether err is S3 answering that a key does not exist: NoSuchKey, 404, or
+// 403, which is how S3 answers a missing key to callers without s3:ListBucket.
+func missing(err error) bool {
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+
+	status := respErr.HTTPStatusCode()
+
+	return status == http.StatusNotFound || status == http.StatusForbidden
+}
+
+// Put uploads value under key.
+func (c *S3) Put(ctx context.Context, key string, value []byte) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	_, err := c.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.Bucket),
+		Key:         aws.String(c.Prefix + key),
+		Body:        bytes.NewReader(value),
+		ContentType: aws.String("application/pdf"),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading cache entry %s: %w", key, err)
+	}
+
+	return nil
+}
@@ -3,102 +3,197 @@ package connection
 import (
 	"context"
 	"database/sql"
-	"log"
-	"log/slog"
-	"os"
+	"errors"
+	"fmt"
 
-	service_sign "biblebrain-services/service/sign"
+	"biblebrain-services/config"
+	secret_service "biblebrain-services/service/secret"
+	util "biblebrain-services/util"
 
 	"github.com/go-sql-driver/mysql"
 	sqldblogger "github.com/simukti/sqldb-logger"
 )
 
 // SlogAdapter struct to adapt the slog to sqldb-logger's Logger interface.
-type SlogAdapter struct{}
+// Query text and arguments pass through Redactor before they are logged.
+type SlogAdapter struct {
+	Redactor *util.Redactor
+}
+
+// Log method to satisfy sqldb-logger's Logger interface. Records go to the logger of ctx
+// so that queries can be traced to the request that issued them.
+func (l SlogAdapter) Log(ctx context.Context, level sqldblogger.Level, msg string, data map[string]interface{}) {
+	redactor := l.Redactor
+	if redactor == nil {
+		redactor = util.DefaultRedactor()
+	}
+
+	data = redactor.RedactQueryData(data)
+	logger := util.LoggerFrom(ctx)
 
-// Log method to satisfy sqldb-logger's Logger interface.
-func (l SlogAdapter) Log(_ context.Context, level sqldblogger.Level, msg string, data map[string]interface{}) {
 	// Adapt this method according to how slog accepts log messages.
 	switch level {
 	case sqldblogger.LevelError:
-		slog.Error(msg, "data", data)
+		logger.Error(msg, "data", data)
 	case sqldblogger.LevelInfo:
 		// Check if the log is from a QueryContext
 		if msg == "QueryContext" {
-			slog.Debug(msg, "data", data)
+			logger.Debug(msg, "data", data)
 		} else {
-			slog.Info(msg, "data", data)
+			logger.Info(msg, "data", data)
 		}
 	case sqldblogger.LevelDebug:
-		slog.Debug(msg, "data", data)
+		logger.Debug(msg, "data", data)
 	case sqldblogger.LevelTrace:
-		slog.Warn(msg, "data", data)
+		logger.Warn(msg, "data", data)
 	default:
-		slog.Info("Unhandled log level", "level", level, "msg", msg, "data", data)
+		logger.Info("Unhandled log level", "level", level, "msg", msg, "data", data)
 		// Add other cases as needed.
 	}
 }
 
-func GetBibleBrainDB(ctx context.Context) *sql.DB {
-	config := getBibleBrainSQLConfig(ctx)
-	dsn := config.FormatDSN()
-	slog.Debug("MYSQL_CONNECT_STRING", "dsn", dsn)
+// Errors returned while resolving and opening the BibleBrain database.
+var ErrConfigMissing = errors.New("database configuration missing")
+
+var ErrSecretUnavailable = errors.New("database secret unavailable")
+
+var ErrDBUnreachable = errors.New("database unreachable")
+
+// GetBibleBrainDB opens the BibleBrain database using an uncached provider built from cfg.Secrets.
+// Long-lived processes should use a Manager instead.
+func GetBibleBrainDB(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	provider, err := secret_service.New(ctx, cfg.Secrets)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSecretUnavailable, err)
+	}
+
+	return OpenBibleBrainDB(ctx, cfg, provider)
+}
+
+// OpenBibleBrainDB resolves the DSN stored under cfg.DSNSecretName() and opens the database.
+// If the database rejects the credentials and provider can refresh, the DSN is re-fetched
+// once in case the password was rotated since it was cached.
+func OpenBibleBrainDB(ctx context.Context, cfg *config.Config, provider secret_service.Provider) (*sql.DB, error) {
+	secretName := cfg.DSNSecretName()
+
+	conn, err := openBibleBrainDB(ctx, cfg, provider, secretName)
+	if err == nil || !isAccessDenied(err) {
+		return conn, err
+	}
+
+	refresher, ok := provider.(secret_service.Refresher)
+	if !ok {
+		return nil, err
+	}
+
+	util.LoggerFrom(ctx).Warn("database rejected credentials, refreshing DSN secret", "secret", secretName)
+
+	if _, refreshErr := refresher.Refresh(ctx, secretName); refreshErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSecretUnavailable, refreshErr)
+	}
+
+	return openBibleBrainDB(ctx, cfg, provider, secretName)
+}
+
+func openBibleBrainDB(
+	ctx context.Context,
+	cfg *config.Config,
+	provider secret_service.Provider,
+	secretName string,
+) (*sql.DB, error) {
+	sqlConfig, err := getBibleBrainSQLConfig(ctx, provider, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := sqlConfig.FormatDSN()
+	util.LoggerFrom(ctx).Debug("MYSQL_CONNECT_STRING", "dsn", util.RedactDSN(dsn))
 	databaseInfo := dsn + "?parseTime=true&interpolateParams=true"
 	conn, err := sql.Open("mysql", databaseInfo)
 	if err != nil {
-		log.Panic(err)
+		return nil, fmt.Errorf("%w: %w", ErrConfigMissing, err)
 	}
 
-	PingDB(conn)
+	if err := PingDB(ctx, conn); err != nil {
+		conn.Close()
+
+		return nil, err
+	}
+
+	if cfg.Environment != config.EnvironmentProd {
+		redactor, err := cfg.Log.Redactor()
+		if err != nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("%w: %w", ErrConfigMissing, err)
+		}
 
-	environment := os.Getenv("environment")
-	if environment != "prod" {
 		sqlCon := sqldblogger.OpenDriver(
 			databaseInfo,
 			conn.Driver(),
-			SlogAdapter{},
+			SlogAdapter{Redactor: redactor},
 		)
+		// The logging handle opens its own connections; release the one used for the ping.
+		conn.Close()
 
-		return sqlCon
+		return sqlCon, nil
 	}
 
-	return conn
+	return conn, nil
 }
 
-func PingDB(conn *sql.DB) {
-	slog.Info("attempting ping")
-	err := conn.Ping()
+// isAccessDenied reports whether err is MySQL's ER_ACCESS_DENIED_ERROR.
+func isAccessDenied(err error) bool {
+	const erAccessDenied = 1045
+
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == erAccessDenied
+}
+
+func PingDB(ctx context.Context, conn *sql.DB) error {
+	util.LoggerFrom(ctx).Info("attempting ping")
+	err := conn.PingContext(ctx)
 	if err != nil {
-		log.Panic(err)
+		return fmt.Errorf("%w: %w", ErrDBUnreachable, err)
 	}
 
-	slog.Info("success pinging datasource")
-}
+	util.LoggerFrom(ctx).Info("success pinging datasource")
 
-func getBiblebrainDSN(ctx context.Context) string {
-	environment := os.Getenv("environment")
+	return nil
+}
 
-	if environment == "local" {
-		return os.Getenv("BIBLEBRAIN_DSN")
+func getBiblebrainDSN(ctx context.Context, provider secret_service.Provider, secretName string) (string, error) {
+	if secretName == "" {
+		return "", fmt.Errorf("%w: DSN secret name not set", ErrConfigMissing)
 	}
 
-	ssmClient := service_sign.GetSSMClient(ctx)
-	parameterName := os.Getenv("BIBLEBRAIN_DSN_SSM_ID")
+	dsn, err := provider.GetSecret(ctx, secretName)
+	if err != nil {
+		if errors.Is(err, secret_service.ErrNotFound) {
+			return "", fmt.Errorf("%w: %w", ErrConfigMissing, err)
+		}
+
+		return "", fmt.Errorf("%w: %w", ErrSecretUnavailable, err)
+	}
 
-	return *service_sign.GetSsmParameter(ctx, ssmClient, parameterName)
+	return dsn, nil
 }
 
-func getBibleBrainSQLConfig(ctx context.Context) *mysql.Config {
-	dsn := getBiblebrainDSN(ctx)
+func getBibleBrainSQLConfig(ctx context.Context, provider secret_service.Provider, secretName string) (*mysql.Config, error) {
+	dsn, err := getBiblebrainDSN(ctx, provider, secretName)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(dsn) < 1 {
-		panic("BIBLEBRAIN_DSN not set")
+		return nil, fmt.Errorf("%w: BIBLEBRAIN_DSN not set", ErrConfigMissing)
 	}
 
-	config, err := mysql.ParseDSN(dsn)
+	sqlConfig, err := mysql.ParseDSN(dsn)
 	if err != nil {
-		panic("unable to parse BIBLEBRAIN_DSN ")
+		return nil, fmt.Errorf("%w: unable to parse BIBLEBRAIN_DSN: %w", ErrConfigMissing, err)
 	}
 
-	return config
+	return sqlConfig, nil
 }
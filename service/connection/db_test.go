@@ -0,0 +1,36 @@
+package connection_test
+
+import (
+	"testing"
+
+	"biblebrain-services/config"
+	connection_service "biblebrain-services/service/connection"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetBibleBrainDBConfigMissing verifies that a missing DSN is reported as ErrConfigMissing.
+func TestGetBibleBrainDBConfigMissing(t *testing.T) {
+	t.Setenv("environment", "local")
+	t.Setenv("BIBLEBRAIN_DSN", "")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	conn, err := connection_service.GetBibleBrainDB(t.Context(), cfg)
+	require.ErrorIs(t, err, connection_service.ErrConfigMissing)
+	require.Nil(t, conn)
+}
+
+// TestGetBibleBrainDBUnreachable verifies that a failed ping is reported as ErrDBUnreachable.
+func TestGetBibleBrainDBUnreachable(t *testing.T) {
+	t.Setenv("environment", "local")
+	t.Setenv("BIBLEBRAIN_DSN", "user:secret@tcp(127.0.0.1:1)/biblebrain")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	conn, err := connection_service.GetBibleBrainDB(t.Context(), cfg)
+	require.ErrorIs(t, err, connection_service.ErrDBUnreachable)
+	require.Nil(t, conn)
+}
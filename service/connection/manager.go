@@ -0,0 +1,224 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"biblebrain-services/config"
+	secret_service "biblebrain-services/service/secret"
+	util "biblebrain-services/util"
+)
+
+// PoolOptions configures the database/sql connection pool held by a Manager.
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// HealthCheckInterval is how long a pool is trusted before DB pings it again.
+	HealthCheckInterval time.Duration
+}
+
+// PoolOptionsFrom returns the pool settings of the database configuration.
+func PoolOptionsFrom(cfg config.Database) PoolOptions {
+	return PoolOptions{
+		MaxOpenConns:        cfg.MaxOpenConns,
+		MaxIdleConns:        cfg.MaxIdleConns,
+		ConnMaxLifetime:     cfg.ConnMaxLifetime,
+		ConnMaxIdleTime:     cfg.ConnMaxIdleTime,
+		HealthCheckInterval: cfg.HealthCheckInterval,
+	}
+}
+
+// DefaultPoolOptions returns the pool settings of the default configuration.
+func DefaultPoolOptions() PoolOptions {
+	return PoolOptionsFrom(config.Default().Database)
+}
+
+// Opener opens a new database handle.
+type Opener func(ctx context.Context) (*sql.DB, error)
+
+// Bounds of the health checks and re-opens run by DB, which outlive the request that
+// triggered them.
+const (
+	pingTimeout = 5 * time.Second
+	openTimeout = 30 * time.Second
+	// drainTimeout is how long a replaced pool stays open for the requests still using it.
+	drainTimeout = 5 * time.Minute
+)
+
+// Manager owns a single pooled *sql.DB for the lifetime of a Lambda container or server
+// process. The pool is opened on first use and replaced if a health check fails.
+type Manager struct {
+	mu        sync.Mutex
+	opts      PoolOptions
+	open      Opener
+	db        *sql.DB
+	checkedAt time.Time
+	// refreshing is closed when the health check or open in progress ends; nil when none is.
+	refreshing chan struct{}
+	// refreshErr is the error of the last refresh, returned to the callers that waited for it.
+	refreshErr error
+	// retired are replaced pools that are closed once drained.
+	retired []*sql.DB
+}
+
+// NewManager returns a Manager that opens its pool with open.
+func NewManager(opts PoolOptions, open Opener) *Manager {
+	return &Manager{opts: opts, open: open}
+}
+
+// NewBibleBrainManager returns a Manager for the BibleBrain database described by cfg,
+// whose DSN is resolved through provider.
+func NewBibleBrainManager(cfg *config.Config, provider secret_service.Provider) *Manager {
+	return NewManager(PoolOptionsFrom(cfg.Database), func(ctx context.Context) (*sql.DB, error) {
+		return OpenBibleBrainDB(ctx, cfg, provider)
+	})
+}
+
+// DB returns the shared pool, opening it on first use. If the pool has not been checked
+// within HealthCheckInterval it is pinged, and replaced when the ping fails.
+//
+// The check and the open run once for all concurrent callers, detached from their
+// cancellation and bounded by their own timeouts, so a caller that gives up neither fails
+// the pool nor blocks the others. A replaced pool is closed only after drainTimeout, since
+// other requests may still hold it.
+func (m *Manager) DB(ctx context.Context) (*sql.DB, error) {
+	m.mu.Lock()
+
+	if m.db != nil && time.Since(m.checkedAt) < m.opts.HealthCheckInterval {
+		db := m.db
+		m.mu.Unlock()
+
+		return db, nil
+	}
+
+	if m.refreshing == nil {
+		m.refreshing = make(chan struct{})
+		go m.refresh(context.WithoutCancel(ctx), m.db, m.refreshing)
+	}
+
+	done := m.refreshing
+	m.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for database: %w", ctx.Err())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.refreshErr != nil {
+		return nil, m.refreshErr
+	}
+
+	return m.db, nil
+}
+
+// refresh pings current, or opens a new pool when there is none or the ping fails, then
+// closes done.
+func (m *Manager) refresh(ctx context.Context, current *sql.DB, done chan struct{}) {
+	defer close(done)
+
+	logger := util.LoggerFrom(ctx)
+
+	if current != nil {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := current.PingContext(pingCtx)
+
+		cancel()
+
+		if err == nil {
+			m.mu.Lock()
+			m.checkedAt = time.Now()
+			m.refreshing = nil
+			m.refreshErr = nil
+			m.mu.Unlock()
+
+			return
+		}
+
+		logger.Warn("database health check failed, reconnecting", "error", err)
+	}
+
+	openCtx, cancel := context.WithTimeout(ctx, openTimeout)
+	conn, err := m.open(openCtx)
+
+	cancel()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refreshing = nil
+
+	if err != nil {
+		// The current pool is kept for the requests using it, and checked again on the next call.
+		m.refreshErr = fmt.Errorf("opening database: %w", err)
+
+		return
+	}
+
+	conn.SetMaxOpenConns(m.opts.MaxOpenConns)
+	conn.SetMaxIdleConns(m.opts.MaxIdleConns)
+	conn.SetConnMaxLifetime(m.opts.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(m.opts.ConnMaxIdleTime)
+
+	if m.db != nil {
+		m.retire(ctx, m.db)
+	}
+
+	m.db = conn
+	m.checkedAt = time.Now()
+	m.refreshErr = nil
+}
+
+// retire closes old after drainTimeout, or on Close. m.mu must be held.
+func (m *Manager) retire(ctx context.Context, old *sql.DB) {
+	m.retired = append(m.retired, old)
+
+	time.AfterFunc(drainTimeout, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if i := slices.Index(m.retired, old); i >= 0 {
+			m.retired = slices.Delete(m.retired, i, i+1)
+
+			if err := old.Close(); err != nil {
+				util.LoggerFrom(ctx).Warn("closing replaced database pool", "error", err)
+			}
+		}
+	})
+}
+
+// Close releases the pool and any replaced pools. The Manager can still be used afterwards;
+// DB re-opens it.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+
+	for _, old := range m.retired {
+		errs = append(errs, old.Close())
+	}
+
+	m.retired = nil
+
+	if m.db != nil {
+		errs = append(errs, m.db.Close())
+		m.db = nil
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("closing database: %w", err)
+	}
+
+	return nil
+}
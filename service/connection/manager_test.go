@@ -0,0 +1,179 @@
+package connection_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	connection_service "biblebrain-services/service/connection"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errPingFailed = errors.New("ping failed")
+
+// fakeDriver is a minimal database/sql driver whose pings fail while failPing is set.
+type fakeDriver struct {
+	failPing atomic.Bool
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{driver: d}, nil }
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c *fakeConn) Ping(context.Context) error {
+	if c.driver.failPing.Load() {
+		return errPingFailed
+	}
+
+	return nil
+}
+
+type fakeConnector struct {
+	driver *fakeDriver
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c fakeConnector) Driver() driver.Driver                        { return c.driver }
+
+// newFakeManager returns a Manager backed by fakeDriver and a counter of opened pools.
+func newFakeManager(opts connection_service.PoolOptions) (*connection_service.Manager, *fakeDriver, *atomic.Int32) {
+	drv := &fakeDriver{}
+	opened := &atomic.Int32{}
+
+	mgr := connection_service.NewManager(opts, func(context.Context) (*sql.DB, error) {
+		opened.Add(1)
+
+		return sql.OpenDB(fakeConnector{drv}), nil
+	})
+
+	return mgr, drv, opened
+}
+
+// TestManagerReusesPool verifies that repeated calls share one pool with the configured limits.
+func TestManagerReusesPool(t *testing.T) {
+	t.Parallel()
+
+	opts := connection_service.DefaultPoolOptions()
+	mgr, _, opened := newFakeManager(opts)
+
+	first, err := mgr.DB(t.Context())
+	require.NoError(t, err)
+	second, err := mgr.DB(t.Context())
+	require.NoError(t, err)
+
+	require.Same(t, first, second)
+	require.Equal(t, int32(1), opened.Load())
+	require.Equal(t, opts.MaxOpenConns, first.Stats().MaxOpenConnections)
+	require.NoError(t, mgr.Close())
+}
+
+// TestManagerReconnectsAfterFailedHealthCheck verifies that a failed ping re-opens the pool.
+func TestManagerReconnectsAfterFailedHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	opts := connection_service.DefaultPoolOptions()
+	opts.HealthCheckInterval = time.Nanosecond
+	mgr, drv, opened := newFakeManager(opts)
+
+	_, err := mgr.DB(t.Context())
+	require.NoError(t, err)
+
+	drv.failPing.Store(true)
+	time.Sleep(time.Millisecond)
+
+	_, err = mgr.DB(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), opened.Load())
+	require.NoError(t, mgr.Close())
+}
+
+// TestManagerKeepsReplacedPool verifies that a pool replaced after a failed health check is
+// not closed under the requests still holding it.
+func TestManagerKeepsReplacedPool(t *testing.T) {
+	t.Parallel()
+
+	opts := connection_service.DefaultPoolOptions()
+	opts.HealthCheckInterval = time.Nanosecond
+	mgr, drv, _ := newFakeManager(opts)
+
+	first, err := mgr.DB(t.Context())
+	require.NoError(t, err)
+
+	drv.failPing.Store(true)
+	time.Sleep(time.Millisecond)
+
+	second, err := mgr.DB(t.Context())
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+
+	conn, err := first.Conn(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	require.NoError(t, mgr.Close())
+}
+
+// TestManagerIgnoresCallerCancellation verifies that a caller giving up neither fails nor
+// replaces the pool.
+func TestManagerIgnoresCallerCancellation(t *testing.T) {
+	t.Parallel()
+
+	opts := connection_service.DefaultPoolOptions()
+	opts.HealthCheckInterval = time.Nanosecond
+	mgr, _, opened := newFakeManager(opts)
+
+	first, err := mgr.DB(t.Context())
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, _ = mgr.DB(ctx)
+
+	second, err := mgr.DB(t.Context())
+	require.NoError(t, err)
+	require.Same(t, first, second)
+	require.Equal(t, int32(1), opened.Load())
+	require.NoError(t, mgr.Close())
+}
+
+// TestManagerOpenFailure verifies that a failed open is reported to the waiting callers and
+// retried on the next call.
+func TestManagerOpenFailure(t *testing.T) {
+	t.Parallel()
+
+	var fail atomic.Bool
+
+	fail.Store(true)
+
+	mgr := connection_service.NewManager(connection_service.DefaultPoolOptions(),
+		func(context.Context) (*sql.DB, error) {
+			if fail.Load() {
+				return nil, errPingFailed
+			}
+
+			return sql.OpenDB(fakeConnector{&fakeDriver{}}), nil
+		})
+
+	_, err := mgr.DB(t.Context())
+	require.ErrorIs(t, err, errPingFailed)
+
+	fail.Store(false)
+
+	db, err := mgr.DB(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, db)
+	require.NoError(t, mgr.Close())
+}
@@ -0,0 +1,363 @@
+package copyright
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	logo_service "biblebrain-services/service/logo"
+	pdf_service "biblebrain-services/service/pdf"
+	sqlc "biblebrain-services/sqlc/generated"
+	util "biblebrain-services/util"
+)
+
+// AuditIssueKind identifies the category of a data-quality problem reported by Audit.
+type AuditIssueKind string
+
+const (
+	AuditEmptyCopyright       AuditIssueKind = "empty_copyright"
+	AuditMissingOrganizations AuditIssueKind = "missing_organizations"
+	AuditInvalidOrgIDList     AuditIssueKind = "invalid_organization_id_list"
+	// AuditMissingTranslation keeps its original value, from when names were only in English.
+	AuditMissingTranslation AuditIssueKind = "missing_english_translation"
+	AuditLogoUnreachable    AuditIssueKind = "logo_unreachable"
+	AuditLogoUndecodable    AuditIssueKind = "logo_undecodable"
+	AuditSVGUnrenderable    AuditIssueKind = "svg_unrenderable"
+)
+
+// AuditOptions narrows the scope of an audit run.
+type AuditOptions struct {
+	// ProductCodes restricts the audit to the given products. Empty means every product.
+	ProductCodes []string
+	// Mode restricts the audit to filesets of the given mode. Empty means every mode.
+	Mode string
+	// CheckLogos downloads and decodes every organization logo referenced by the scanned products.
+	CheckLogos bool
+}
+
+// AuditIssue describes a single data-quality problem.
+type AuditIssue struct {
+	Kind           AuditIssueKind `json:"kind"`
+	ProductCode    string         `json:"productCode,omitempty"`
+	OrganizationID uint           `json:"organizationId,omitempty"`
+	LogoURL        string         `json:"logoUrl,omitempty"`
+	Detail         string         `json:"detail"`
+}
+
+// AuditReport is the result of an audit run.
+type AuditReport struct {
+	Mode                 string       `json:"mode"`
+	ProductsScanned      int          `json:"productsScanned"`
+	OrganizationsScanned int          `json:"organizationsScanned"`
+	LogosScanned         int          `json:"logosScanned"`
+	Issues               []AuditIssue `json:"issues"`
+}
+
+// WriteCSV writes the report issues as CSV, one issue per row.
+func (r AuditReport) WriteCSV(writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+
+	header := []string{"kind", "product_code", "organization_id", "logo_url", "detail"}
+	if err := csvWriter.Write(header); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+
+	for _, issue := range r.Issues {
+		orgID := ""
+		if issue.OrganizationID != 0 {
+			orgID = strconv.FormatUint(uint64(issue.OrganizationID), 10)
+		}
+
+		record := []string{string(issue.Kind), issue.ProductCode, orgID, issue.LogoURL, issue.Detail}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("writing CSV record: %w", err)
+		}
+	}
+
+	csvWriter.Flush()
+
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("flushing CSV: %w", err)
+	}
+
+	return nil
+}
+
+// allTypeCodes returns the type codes of every supported mode.
+func allTypeCodes() []string {
+	codes := make([]string, 0)
+	for _, mode := range []string{ModeAudio, ModeVideo, ModeText} {
+		codes = append(codes, getTypeCodes(mode)...)
+	}
+
+	return codes
+}
+
+// auditCopyrightRow is the subset of columns shared by the audit copyright queries.
+type auditCopyrightRow struct {
+	ProductCode        string
+	Copyright          string
+	OrganizationIDList string
+}
+
+// Audit scans copyright records and their organizations and reports data-quality problems
+// that would otherwise only surface as a broken PDF.
+func (m *Manager) Audit(ctx context.Context, opts AuditOptions) (AuditReport, error) {
+	report := AuditReport{Mode: opts.Mode, Issues: []AuditIssue{}}
+
+	rows, err := m.auditCopyrightRows(ctx, opts)
+	if err != nil {
+		return report, err
+	}
+
+	// 1) Copyright text and organization ID lists. Products have a row per fileset, so
+	// they are listed once, in the order of their first row.
+	var products []string
+
+	orgsByProduct := make(map[string][]uint32, len(rows))
+	idSet := make(map[uint32]struct{})
+
+	for _, row := range rows {
+		if _, seen := orgsByProduct[row.ProductCode]; !seen {
+			products = append(products, row.ProductCode)
+			orgsByProduct[row.ProductCode] = nil
+		}
+
+		if strings.TrimSpace(row.Copyright) == "" {
+			report.Issues = append(report.Issues, AuditIssue{
+				Kind:        AuditEmptyCopyright,
+				ProductCode: row.ProductCode,
+				Detail:      "copyright text is empty",
+			})
+		}
+
+		if strings.TrimSpace(row.OrganizationIDList) == "" {
+			report.Issues = append(report.Issues, AuditIssue{
+				Kind:        AuditMissingOrganizations,
+				ProductCode: row.ProductCode,
+				Detail:      "no organizations are linked to the copyright",
+			})
+
+			continue
+		}
+
+		for part := range strings.SplitSeq(row.OrganizationIDList, ",") {
+			s := strings.TrimSpace(part)
+			id64, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				report.Issues = append(report.Issues, AuditIssue{
+					Kind:        AuditInvalidOrgIDList,
+					ProductCode: row.ProductCode,
+					Detail:      fmt.Sprintf("cannot parse organization ID %q in %q", s, row.OrganizationIDList),
+				})
+
+				continue
+			}
+			if !slices.Contains(orgsByProduct[row.ProductCode], uint32(id64)) {
+				orgsByProduct[row.ProductCode] = append(orgsByProduct[row.ProductCode], uint32(id64))
+			}
+
+			idSet[uint32(id64)] = struct{}{}
+		}
+	}
+
+	orgIDs := make([]uint32, 0, len(idSet))
+	for id := range idSet {
+		orgIDs = append(orgIDs, id)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	// 2) Organizations and their English translations
+	var orgRows []sqlc.GetOrganizationsForAuditRow
+	if len(orgIDs) > 0 {
+		orgRows, err = m.Query.GetOrganizationsForAudit(ctx, sqlc.GetOrganizationsForAuditParams{
+			LanguageId:      m.Config.LanguageID,
+			OrganizationsId: orgIDs,
+		})
+		if err != nil {
+			util.LoggerFrom(ctx).Error("fetching organizations for audit", "error", err)
+
+			return report, fmt.Errorf("GetOrganizationsForAudit: %w", err)
+		}
+	}
+
+	report.ProductsScanned = len(products)
+
+	known := make(map[uint32]struct{}, len(orgRows))
+	logoOrgs := make(map[string][]uint32)
+
+	for _, o := range orgRows {
+		_, seen := known[o.OrganizationID]
+		if !seen && !o.OrganizationName.Valid {
+			report.Issues = append(report.Issues, AuditIssue{
+				Kind:           AuditMissingTranslation,
+				OrganizationID: uint(o.OrganizationID),
+				Detail: fmt.Sprintf(
+					"organization %q has no translation for language %d", o.OrganizationSlug, m.Config.LanguageID,
+				),
+			})
+		}
+		known[o.OrganizationID] = struct{}{}
+
+		// Organizations may share a logo URL, and each of them is reported with it.
+		if url := o.OrganizationLogoUrl.String; o.OrganizationLogoUrl.Valid && url != "" &&
+			!slices.Contains(logoOrgs[url], o.OrganizationID) {
+			logoOrgs[url] = append(logoOrgs[url], o.OrganizationID)
+		}
+	}
+
+	report.OrganizationsScanned = len(known)
+
+	for _, product := range products {
+		for _, id := range orgsByProduct[product] {
+			if _, ok := known[id]; !ok {
+				report.Issues = append(report.Issues, AuditIssue{
+					Kind:           AuditMissingOrganizations,
+					ProductCode:    product,
+					OrganizationID: uint(id),
+					Detail:         fmt.Sprintf("organization %d does not exist", id),
+				})
+			}
+		}
+	}
+
+	// 3) Logos
+	if opts.CheckLogos {
+		report.LogosScanned = len(logoOrgs)
+		report.Issues = append(report.Issues, m.auditLogos(ctx, logoOrgs)...)
+	}
+
+	return report, nil
+}
+
+// auditCopyrightRows fetches the copyright rows in scope for an audit.
+func (m *Manager) auditCopyrightRows(ctx context.Context, opts AuditOptions) ([]auditCopyrightRow, error) {
+	typeCodes := getTypeCodes(opts.Mode)
+	if opts.Mode == "" {
+		typeCodes = allTypeCodes()
+	}
+
+	if len(opts.ProductCodes) == 0 {
+		rows, err := m.Query.ListFilesetCopyrightsForAudit(ctx, typeCodes)
+		if err != nil {
+			util.LoggerFrom(ctx).Error("listing fileset copyrights for audit", "error", err)
+
+			return nil, fmt.Errorf("ListFilesetCopyrightsForAudit: %w", err)
+		}
+
+		out := make([]auditCopyrightRow, 0, len(rows))
+		for _, r := range rows {
+			out = append(out, auditCopyrightRow{r.ProductCode, r.Copyright, r.OrganizationIDList.String})
+		}
+
+		return out, nil
+	}
+
+	rows, err := m.Query.GetFilesetCopyrightsForAudit(ctx, sqlc.GetFilesetCopyrightsForAuditParams{
+		ProductCodes: opts.ProductCodes,
+		TypeCodes:    typeCodes,
+	})
+	if err != nil {
+		util.LoggerFrom(ctx).Error("fetching fileset copyrights for audit", "error", err)
+
+		return nil, fmt.Errorf("GetFilesetCopyrightsForAudit: %w", err)
+	}
+
+	out := make([]auditCopyrightRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, auditCopyrightRow{r.ProductCode, r.Copyright, r.OrganizationIDList.String})
+	}
+
+	return out, nil
+}
+
+// auditLogos fetches every logo through the logo fetcher and checks that it can be decoded,
+// rendering SVGs to PNG first. It returns an issue per broken logo and organization using it.
+func (m *Manager) auditLogos(ctx context.Context, logoOrgs map[string][]uint32) []AuditIssue {
+	urls := make([]string, 0, len(logoOrgs))
+	for logoURL := range logoOrgs {
+		urls = append(urls, logoURL)
+	}
+	sort.Strings(urls)
+
+	logos := m.logos()
+	box := m.logoBox(pdf_service.Configuration())
+	channel := make(chan *AuditIssue, len(urls))
+	sem := make(chan struct{}, m.Config.MaxConcurrentDownloads)
+
+	for _, logoURL := range urls {
+		sem <- struct{}{}
+		go func(logoURL string) {
+			defer func() { <-sem }()
+
+			_, issue := auditLogo(ctx, logos, box, logoURL)
+			channel <- issue
+		}(logoURL)
+	}
+
+	issues := make([]AuditIssue, 0)
+	for range urls {
+		if issue := <-channel; issue != nil {
+			issues = append(issues, issuesByOrganization(*issue, logoOrgs[issue.LogoURL])...)
+		}
+	}
+
+	sortLogoIssues(issues)
+
+	return issues
+}
+
+// issuesByOrganization returns a copy of the logo issue for each organization of orgIDs.
+func issuesByOrganization(issue AuditIssue, orgIDs []uint32) []AuditIssue {
+	issues := make([]AuditIssue, 0, len(orgIDs))
+	for _, id := range orgIDs {
+		issue.OrganizationID = uint(id)
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// sortLogoIssues sorts logo issues by URL, then organization.
+func sortLogoIssues(issues []AuditIssue) {
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].LogoURL != issues[j].LogoURL {
+			return issues[i].LogoURL < issues[j].LogoURL
+		}
+
+		return issues[i].OrganizationID < issues[j].OrganizationID
+	})
+}
+
+// auditLogo fetches and normalizes a single logo, returning it, or an issue if the logo is
+// not usable.
+func auditLogo(ctx context.Context, logos LogoFetcher, box LogoBox, logoURL string) (logo_service.Logo, *AuditIssue) {
+	logo, err := logos.Get(ctx, logoURL)
+	if err != nil {
+		detail := err.Error()
+
+		var statusErr *logo_service.StatusError
+		if errors.As(err, &statusErr) {
+			detail = fmt.Sprintf("logo download returned %d", statusErr.StatusCode)
+		}
+
+		return logo, &AuditIssue{Kind: AuditLogoUnreachable, LogoURL: logoURL, Detail: detail}
+	}
+
+	if _, err := NormalizeLogo(ctx, logo.Path, box); err != nil {
+		kind := AuditLogoUndecodable
+		if logo.Ext == SVGFormat {
+			kind = AuditSVGUnrenderable
+		}
+
+		return logo, &AuditIssue{Kind: kind, LogoURL: logoURL, Detail: err.Error()}
+	}
+
+	return logo, nil
+}
@@ -0,0 +1,70 @@
+package copyright_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"biblebrain-services/config"
+	connection_service "biblebrain-services/service/connection"
+	copyright_service "biblebrain-services/service/copyright"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuditReportWriteCSV verifies that the CSV output has a header row and one row per issue.
+func TestAuditReportWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	report := copyright_service.AuditReport{
+		Issues: []copyright_service.AuditIssue{
+			{Kind: copyright_service.AuditEmptyCopyright, ProductCode: "N2ENG/NIV", Detail: "copyright text is empty"},
+			{
+				Kind:           copyright_service.AuditLogoUnreachable,
+				OrganizationID: 42,
+				LogoURL:        "https://example.com/logo.png",
+				Detail:         "logo download returned 404",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteCSV(&buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"kind", "product_code", "organization_id", "logo_url", "detail"},
+		{"empty_copyright", "N2ENG/NIV", "", "", "copyright text is empty"},
+		{"logo_unreachable", "", "42", "https://example.com/logo.png", "logo download returned 404"},
+	}, records)
+}
+
+// TestAuditIntegration verifies that Audit scans the requested products against a real database.
+func TestAuditIntegration(t *testing.T) {
+	t.Parallel()
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	sqlCon, err := connection_service.GetBibleBrainDB(t.Context(), cfg)
+	require.NoError(t, err)
+
+	defer sqlCon.Close()
+
+	mgr := copyright_service.New(sqlCon, cfg.Copyright)
+
+	codes := []string{"P1PUI/LAN", "N2SWA/HNV", "N2POR/BSP", "N2ENG/NIV", "P1KEB/CIE"}
+	report, err := mgr.Audit(t.Context(), copyright_service.AuditOptions{
+		ProductCodes: codes,
+		Mode:         "audio",
+		CheckLogos:   true,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, report.ProductsScanned, "expected at least one product to be scanned")
+	require.LessOrEqual(t, report.ProductsScanned, len(codes), "products are counted once, not per fileset")
+
+	for _, issue := range report.Issues {
+		require.NotEmpty(t, issue.Kind)
+		require.NotEmpty(t, issue.Detail)
+	}
+}
@@ -0,0 +1,218 @@
+package copyright
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BatchPackage is a download package of a batch manifest.
+type BatchPackage struct {
+	// Name names the output file, without extension; it must be unique in the manifest.
+	Name     string   `json:"name"`
+	Products []string `json:"products"`
+	// Mode is ModeAudio (default), ModeVideo or ModeText.
+	Mode string `json:"mode"`
+	// Format is FormatPDF (default) or FormatJSON.
+	Format string `json:"format"`
+	// LanguageID selects the language of organization names; zero uses the configured one.
+	LanguageID uint32 `json:"languageId"`
+}
+
+// FileName returns the name of the output file of p.
+func (p BatchPackage) FileName() string {
+	return p.Name + "." + p.Format
+}
+
+// ErrInvalidManifest is returned for manifests that cannot be read or have invalid packages.
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// ReadManifestJSON reads a manifest written as a JSON array of BatchPackage.
+func ReadManifestJSON(reader io.Reader) ([]BatchPackage, error) {
+	var packages []BatchPackage
+	if err := json.NewDecoder(reader).Decode(&packages); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+
+	return checkManifest(packages)
+}
+
+// ReadManifestCSV reads a manifest written as CSV with a header row naming the columns name,
+// products, and optionally mode, format and language. Products are separated by spaces or
+// semicolons.
+func ReadManifestCSV(reader io.Reader) ([]BatchPackage, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no header row", ErrInvalidManifest)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	for _, required := range []string{"name", "products"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: no %q column", ErrInvalidManifest, required)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+
+		return ""
+	}
+
+	packages := make([]BatchPackage, 0, len(records)-1)
+
+	for line, record := range records[1:] {
+		pkg := BatchPackage{
+			Name: field(record, "name"),
+			Products: strings.FieldsFunc(field(record, "products"), func(r rune) bool {
+				return r == ';' || r == ' '
+			}),
+			Mode:   field(record, "mode"),
+			Format: field(record, "format"),
+		}
+
+		if language := field(record, "language"); language != "" {
+			id, err := strconv.ParseUint(language, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: language %q: %w", ErrInvalidManifest, line+2, language, err)
+			}
+
+			pkg.LanguageID = uint32(id)
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	return checkManifest(packages)
+}
+
+// checkManifest applies the default mode and format to packages and validates them.
+func checkManifest(packages []BatchPackage) ([]BatchPackage, error) {
+	var problems []string
+
+	names := make(map[string]bool, len(packages))
+
+	for i := range packages {
+		pkg := &packages[i]
+		if pkg.Mode == "" {
+			pkg.Mode = ModeAudio
+		}
+
+		if pkg.Format == "" {
+			pkg.Format = FormatPDF
+		}
+
+		label := fmt.Sprintf("package %d (%q)", i+1, pkg.Name)
+
+		switch {
+		case pkg.Name == "" || pkg.Name != filepath.Base(pkg.Name) || strings.HasPrefix(pkg.Name, "."):
+			problems = append(problems, fmt.Sprintf("%s: name must be a plain file name", label))
+		case names[strings.ToLower(pkg.Name)]:
+			problems = append(problems, fmt.Sprintf("%s: duplicate name", label))
+		}
+
+		names[strings.ToLower(pkg.Name)] = true
+
+		if len(pkg.Products) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: no products", label))
+		}
+
+		if !slices.Contains([]string{ModeAudio, ModeVideo, ModeText}, pkg.Mode) {
+			problems = append(problems, fmt.Sprintf("%s: mode %q, only 'audio', 'video' or 'text' is supported",
+				label, pkg.Mode))
+		}
+
+		if pkg.Format != FormatPDF && pkg.Format != FormatJSON {
+			problems = append(problems, fmt.Sprintf("%s: format %q, only 'pdf' or 'json' is supported",
+				label, pkg.Format))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidManifest, strings.Join(problems, "; "))
+	}
+
+	return packages, nil
+}
+
+// BatchStatus is the outcome of a package of a batch.
+type BatchStatus string
+
+const (
+	// BatchGenerated packages were written, possibly without some of their products.
+	BatchGenerated BatchStatus = "generated"
+	// BatchMissing packages have none of their products, and were not written.
+	BatchMissing BatchStatus = "missing"
+	// BatchFailed packages could not be generated.
+	BatchFailed BatchStatus = "failed"
+)
+
+// BatchResult is the outcome of a package of a batch.
+type BatchResult struct {
+	Name   string      `json:"name"`
+	Status BatchStatus `json:"status"`
+	// Output is the path of the generated file.
+	Output string `json:"output,omitempty"`
+	Bytes  int64  `json:"bytes,omitempty"`
+	// MissingProducts are the products of the package without copyrights.
+	MissingProducts []string `json:"missingProducts,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// BatchReport summarizes a batch, with the results in the order of the manifest.
+type BatchReport struct {
+	Packages  int           `json:"packages"`
+	Generated int           `json:"generated"`
+	Missing   int           `json:"missing"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// NewBatchReport summarizes results.
+func NewBatchReport(results []BatchResult) BatchReport {
+	report := BatchReport{Packages: len(results), Results: results}
+
+	for _, result := range results {
+		switch result.Status {
+		case BatchGenerated:
+			report.Generated++
+		case BatchMissing:
+			report.Missing++
+		case BatchFailed:
+			report.Failed++
+		}
+	}
+
+	return report
+}
+
+// MissingProducts returns the products that have no copyright in copyrights.
+func MissingProducts(products []string, copyrights []ByOrganizations) []string {
+	var missing []string
+
+	for _, product := range products {
+		if !slices.ContainsFunc(copyrights, func(copyright ByOrganizations) bool {
+			return copyright.ProductCode == product
+		}) {
+			missing = append(missing, product)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,66 @@
+package copyright_test
+
+import (
+	"strings"
+	"testing"
+
+	copyright_service "biblebrain-services/service/copyright"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadManifest verifies that CSV and JSON manifests give the same packages, with the
+// default mode and format, and that every invalid package is reported.
+func TestReadManifest(t *testing.T) {
+	t.Parallel()
+
+	want := []copyright_service.BatchPackage{
+		{Name: "LAN-audio", Products: []string{"P1PUI/LAN", "N2ENG/NIV"}, Mode: "audio", Format: "pdf"},
+		{Name: "HNV-text", Products: []string{"N2SWA/HNV"}, Mode: "text", Format: "json", LanguageID: 7083},
+	}
+
+	fromCSV, err := copyright_service.ReadManifestCSV(strings.NewReader(
+		"Name,Products,Mode,Format,Language\n" +
+			"LAN-audio,P1PUI/LAN;N2ENG/NIV,,,\n" +
+			"HNV-text, N2SWA/HNV ,text,json,7083\n",
+	))
+	require.NoError(t, err)
+	require.Equal(t, want, fromCSV)
+
+	fromJSON, err := copyright_service.ReadManifestJSON(strings.NewReader(`[
+		{"name": "LAN-audio", "products": ["P1PUI/LAN", "N2ENG/NIV"]},
+		{"name": "HNV-text", "products": ["N2SWA/HNV"], "mode": "text", "format": "json", "languageId": 7083}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, want, fromJSON)
+
+	_, err = copyright_service.ReadManifestCSV(strings.NewReader("name,mode\nLAN,audio\n"))
+	require.ErrorIs(t, err, copyright_service.ErrInvalidManifest)
+
+	_, err = copyright_service.ReadManifestJSON(strings.NewReader(`[
+		{"name": "../LAN", "products": ["P1PUI/LAN"]},
+		{"name": "NIV", "products": [], "mode": "braille"},
+		{"name": "niv", "products": ["N2ENG/NIV"], "format": "docx"}
+	]`))
+	require.ErrorIs(t, err, copyright_service.ErrInvalidManifest)
+
+	for _, problem := range []string{"plain file name", "no products", "braille", "duplicate name", "docx"} {
+		require.ErrorContains(t, err, problem)
+	}
+}
+
+func TestMissingProducts(t *testing.T) {
+	t.Parallel()
+
+	copyrights := []copyright_service.ByOrganizations{{ProductCode: "N2ENG/NIV"}}
+
+	require.Equal(t, []string{"P1PUI/LAN"},
+		copyright_service.MissingProducts([]string{"P1PUI/LAN", "N2ENG/NIV"}, copyrights))
+	require.Empty(t, copyright_service.MissingProducts([]string{"N2ENG/NIV"}, copyrights))
+
+	report := copyright_service.NewBatchReport([]copyright_service.BatchResult{
+		{Status: copyright_service.BatchGenerated}, {Status: copyright_service.BatchMissing},
+		{Status: copyright_service.BatchFailed}, {Status: copyright_service.BatchGenerated},
+	})
+	require.Equal(t, [4]int{4, 2, 1, 1}, [4]int{report.Packages, report.Generated, report.Missing, report.Failed})
+}
@@ -6,18 +6,25 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log/slog"
+	"maps"
 	"math"
-	"os"
-	"path"
-	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"biblebrain-services/config"
+	logo_service "biblebrain-services/service/logo"
+	metrics_service "biblebrain-services/service/metrics"
 	pdf_service "biblebrain-services/service/pdf"
+	tracing_service "biblebrain-services/service/tracing"
 	sqlc "biblebrain-services/sqlc/generated"
+	util "biblebrain-services/util"
 
 	"github.com/go-pdf/fpdf"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -27,6 +34,8 @@ const (
 	ModeAudio  = "audio"
 	ModeVideo  = "video"
 	ModeText   = "text"
+	FormatPDF  = "pdf"
+	FormatJSON = "json"
 )
 
 type Package struct {
@@ -57,6 +66,11 @@ type OrganizationsForCopyright struct {
 	OrganizationSlug    string `json:"organizationSlug"`
 	OrganizationName    string `json:"organizationName"`
 	OrganizationLogoURL string `json:"organizationLogoUrl"`
+	// OrganizationColor is the brand color of the organization, as #rrggbb, if known.
+	OrganizationColor string `json:"organizationColor,omitempty"`
+	// LogoPlaceholder reports that the organization has no usable logo, and is shown with a
+	// monogram of its initials instead.
+	LogoPlaceholder bool `json:"logoPlaceholder"`
 }
 
 type ByOrganizations struct {
@@ -70,11 +84,17 @@ type ByOrganizations struct {
 }
 
 type LogoOrganization struct {
-	URL    string
-	Path   string
-	Ext    string
+	URL string
+	// Path is the normalized logo, of ImageType.
+	Path string
+	// Ext is the format detected from the content of the logo, such as webp.
+	Ext       string
+	ImageType string
+	// Width and Height are the drawn size, in page units.
 	Width  float64
 	Height float64
+	// Placeholder reports that a monogram is drawn instead of a logo.
+	Placeholder bool
 }
 
 func (l LogoOrganization) HasValidPath() bool {
@@ -83,31 +103,95 @@ func (l LogoOrganization) HasValidPath() bool {
 
 // Constants to define the structure and layout of the PDF.
 const (
-	LocalTempFolder        = "/tmp"
-	CopyrightFolder        = "copyright"
-	CopyrightGridAudio     = 4
-	CopyrightGridVideo     = 8
-	DirPerm                = 0o775
-	MaxConcurrentDownloads = 8
+	CopyrightGridAudio = 4
+	CopyrightGridVideo = 8
+	DirPerm            = 0o775
 )
 
 type Service interface {
 	GetCopyrightBy(ctx context.Context, productCodes []string, mode string) ([]ByOrganizations, error)
-	StreamCopyright(ctx context.Context, copyrights []ByOrganizations, mode string) (io.ReadCloser, error)
+	GetVersion(ctx context.Context, productCodes []string, mode string) (Version, error)
+	StreamCopyright(ctx context.Context, copyrights []ByOrganizations, mode string, layout Layout) (io.ReadCloser, error)
+	MarkLogoPlaceholders(ctx context.Context, copyrights []ByOrganizations) []ByOrganizations
+	Audit(ctx context.Context, opts AuditOptions) (AuditReport, error)
+	PrefetchLogos(ctx context.Context, opts PrefetchOptions) (PrefetchReport, error)
+}
+
+// Layout customizes the PDF layout. The zero value selects the defaults.
+type Layout struct {
+	// PageSize is pdf.PageSizeA4 (default) or pdf.PageSizeLetter.
+	PageSize string `json:"pageSize"`
+	// GridSize is the number of cards per page: CopyrightGridAudio or CopyrightGridVideo.
+	// Zero selects the grid of the mode.
+	GridSize int `json:"gridSize"`
+}
+
+// ErrInvalidLayout is returned by Layout.Validate.
+var ErrInvalidLayout = errors.New("invalid layout")
+
+// Validate reports an unsupported page or grid size.
+func (l Layout) Validate() error {
+	if l.PageSize != "" && l.PageSize != pdf_service.PageSizeA4 && l.PageSize != pdf_service.PageSizeLetter {
+		return fmt.Errorf("%w: page size %q, only 'A4' or 'Letter' is supported", ErrInvalidLayout, l.PageSize)
+	}
+
+	if l.GridSize != 0 && l.GridSize != CopyrightGridAudio && l.GridSize != CopyrightGridVideo {
+		return fmt.Errorf("%w: grid size %d, only %d or %d is supported",
+			ErrInvalidLayout, l.GridSize, CopyrightGridAudio, CopyrightGridVideo)
+	}
+
+	return nil
+}
+
+// ForMode returns l with a zero GridSize replaced by the grid of mode: audio packages use
+// the smaller grid, everything else is laid out as video.
+func (l Layout) ForMode(mode string) Layout {
+	if l.GridSize != 0 {
+		return l
+	}
+
+	if mode == ModeAudio {
+		l.GridSize = CopyrightGridAudio
+	} else {
+		l.GridSize = CopyrightGridVideo
+	}
+
+	return l
 }
 
 // Define the struct that implements the interface.
 type Manager struct {
 	Connection *sql.DB
 	Query      *sqlc.Queries
+	Config     config.Copyright
+	// Logos provides organization logos; nil selects logo.Default().
+	Logos LogoFetcher
+}
+
+// LogoFetcher provides organization logos as local files ready to be rendered.
+// *logo.Cache implements it over any logo.Fetcher: HTTP with retries, a local directory,
+// s3:// URLs, or the in-memory logo.Fake that lets PDFs be generated offline.
+type LogoFetcher interface {
+	Get(ctx context.Context, url string) (logo_service.Logo, error)
+	// Lookup returns the entry of a logo already fetched, without fetching it.
+	Lookup(ctx context.Context, url string) (logo_service.Entry, bool)
+}
+
+// logos returns the LogoFetcher of m.
+func (m *Manager) logos() LogoFetcher {
+	if m.Logos != nil {
+		return m.Logos
+	}
+
+	return logo_service.Default()
 }
 
 // Verify at compile-time that *CopyrightService implements Service.
 var _ Service = (*Manager)(nil)
 
 // Constructor for the struct.
-func New(conn *sql.DB) *Manager {
-	return &Manager{Connection: conn, Query: sqlc.New(conn)}
+func New(conn *sql.DB, cfg config.Copyright) *Manager {
+	return &Manager{Connection: conn, Query: sqlc.New(tracing_service.WrapDBTX(conn)), Config: cfg}
 }
 
 var ErrProductsNotFound = errors.New("no copyrights found for the provided product codes")
@@ -119,7 +203,8 @@ var ErrProductsNotFound = errors.New("no copyrights found for the provided produ
 // Parameters:
 //
 //	copyrights: A list of ByOrganizations detailing each copyright request.
-//	isAudio: Boolean flag to determine if the package is audio (true) or video (false).
+//	mode: The package mode; audio packages use a smaller grid unless layout sets one.
+//	layout: Page and grid size overrides.
 //
 // Returns:
 //
@@ -129,25 +214,24 @@ func (m *Manager) StreamCopyright(
 	ctx context.Context,
 	copyrights []ByOrganizations,
 	mode string,
+	layout Layout,
 ) (io.ReadCloser, error) {
 	if len(copyrights) == 0 {
 		return nil, ErrProductsNotFound
 	}
 
-	var gridSize int
-
-	if mode == ModeAudio {
-		gridSize = CopyrightGridAudio
-	} else {
-		gridSize = CopyrightGridVideo
+	if err := layout.Validate(); err != nil {
+		return nil, err
 	}
 
+	layout = layout.ForMode(mode)
+
 	// Create a pipe:
 	reader, writer := io.Pipe()
 
 	go func() {
 		// If ProducePdfCopyright fails, pipe EOF + error downstream.
-		if err := ProducePdfCopyright(ctx, writer, copyrights, gridSize); err != nil {
+		if err := m.ProducePdfCopyright(ctx, writer, copyrights, layout); err != nil {
 			writer.CloseWithError(fmt.Errorf("generating PDF: %w", err))
 		} else {
 			writer.Close()
@@ -180,12 +264,14 @@ func (m *Manager) GetCopyrightBy(
 	typeCodes := getTypeCodes(mode)
 
 	// 1) Fetch the raw rows
+	start := time.Now()
 	rows, err := m.Query.GetFilesetCopyrights(ctx, sqlc.GetFilesetCopyrightsParams{
 		ProductCodes: productCodes,
 		TypeCodes:    typeCodes,
 	})
+	metrics_service.Default().ObserveQuery("GetFilesetCopyrights", time.Since(start))
 	if err != nil {
-		slog.Error("fetching fileset copyrights", "error", err)
+		util.LoggerFrom(ctx).Error("fetching fileset copyrights", "error", err)
 
 		return nil, fmt.Errorf("GetFilesetCopyrights: %w", err)
 	}
@@ -210,9 +296,14 @@ func (m *Manager) GetCopyrightBy(
 	}
 
 	// 4) Fetch organization details
-	orgRows, err := m.Query.GetOrganizations(ctx, orgIDs)
+	start = time.Now()
+	orgRows, err := m.Query.GetOrganizations(ctx, sqlc.GetOrganizationsParams{
+		OrganizationsId: orgIDs,
+		LanguageId:      m.Config.LanguageID,
+	})
+	metrics_service.Default().ObserveQuery("GetOrganizations", time.Since(start))
 	if err != nil {
-		slog.Error("fetching organizations", "error", err)
+		util.LoggerFrom(ctx).Error("fetching organizations", "error", err)
 
 		return nil, fmt.Errorf("GetOrganizations: %w", err)
 	}
@@ -225,6 +316,7 @@ func (m *Manager) GetCopyrightBy(
 			OrganizationSlug:    o.OrganizationSlug,
 			OrganizationName:    o.OrganizationName,
 			OrganizationLogoURL: o.OrganizationLogoUrl.String,
+			OrganizationColor:   o.OrganizationColor.String,
 		}
 	}
 
@@ -259,18 +351,29 @@ func (m *Manager) GetCopyrightBy(
 //
 //	copyrights: A list of ByOrganizations detailing each organization's copyright.
 //	targetPdfFile: The desired path for the resulting PDF file.
-//	gridSize: Specifies the grid size, which determines the number of copyright entries per page.
+//	layout: The page size and the grid size, which determines the number of copyright entries per page.
 //
 // Returns:
 //
 //	error: If there is any error during the PDF generation process, otherwise nil.
-func ProducePdfCopyright(
+func (m *Manager) ProducePdfCopyright(
 	ctx context.Context,
 	writer io.Writer,
 	copyrights []ByOrganizations,
-	gridSize int,
-) error {
-	opts := pdf_service.Configuration()
+	layout Layout,
+) (err error) {
+	gridSize := layout.GridSize
+
+	ctx, span := tracing_service.Tracer().Start(ctx, "ProducePdfCopyright",
+		trace.WithAttributes(
+			attribute.Int("copyrights", len(copyrights)),
+			attribute.Int("grid_size", gridSize),
+			attribute.String("page_size", layout.PageSize),
+		),
+	)
+	defer func() { tracing_service.End(span, err) }()
+
+	opts := pdf_service.ConfigurationFor(layout.PageSize)
 
 	pdf := fpdf.New(opts.PageLayout, opts.PageUnits, opts.PageDimensions, "")
 	pdf.SetTitle("Copyright", false)
@@ -286,55 +389,17 @@ func ProducePdfCopyright(
 	heightByCopyright := make(map[string]float64)
 	copyrightPeerProdCode := make(map[string]ByOrganizations)
 
-	downloadedImages := downloadOrgLogos(ctx, copyrights)
+	logos := m.prepareLogos(ctx, copyrights, opts)
 	placedCards := 0
 	placedTuples := 0
-	logos := make(map[string]LogoOrganization)
 
 	for _, copyright := range copyrights {
 		copyrightPeerProdCode[copyright.ProductCode] = copyright
 
-		for _, org := range copyright.Organizations {
-			logo, exists := downloadedImages[org.OrganizationLogoURL]
-			if !exists {
-				continue
-			}
-
-			imageBaseName := path.Base(logo)
-			imageExt := path.Ext(imageBaseName)
-
-			newPNGImage := logo
-			var imageWidth float64
-			var imageHeight float64
-
-			if imageExt == "."+SVGFormat {
-				newPNGImage, _ = SVGToPNG(logo)
-				imageExt = PNGFormat
-			}
-
-			widthNewPNGImage, heightNewPNGImage, err := GetImageDimensions(newPNGImage)
-			if err != nil {
-				slog.Error("Failed to get image dimensions", "error", err.Error(), "image", newPNGImage)
-
-				continue
-			}
-			imageWidth, imageHeight = ScaleDimensions(widthNewPNGImage, heightNewPNGImage, opts.ImgWidthMax, opts.ImgHeightMax)
-			currentImageHeight := math.Min(imageHeight, opts.ImgHeightMax)
-			logos[org.OrganizationLogoURL] = LogoOrganization{
-				URL:    org.OrganizationLogoURL,
-				Path:   newPNGImage,
-				Ext:    imageExt,
-				Width:  imageWidth,
-				Height: currentImageHeight,
-			}
-		}
-
 		heightByCopyright[copyright.ProductCode] = 6 // header
 		const threshold = 0.5                        // Threshold to avoid too small cards
 		for _, org := range copyright.Organizations {
-			if logoOrganization, ok := logos[org.OrganizationLogoURL]; ok {
-				heightByCopyright[copyright.ProductCode] += logoOrganization.Height
-			}
+			heightByCopyright[copyright.ProductCode] += logoFor(logos, org, opts).Height
 			heightByCopyright[copyright.ProductCode] += pdf_service.CalculateOrgInfoHeight(
 				pdf,
 				org.OrganizationName,
@@ -393,36 +458,180 @@ func ProducePdfCopyright(
 		placedTuples += cardsPerRow
 	}
 
-	if err := pdf.Output(writer); err != nil {
+	counter := &countingWriter{w: writer}
+	if err := pdf.Output(counter); err != nil {
 		return fmt.Errorf("writing PDF to writer: %w", err)
 	}
 
+	metrics_service.Default().ObservePDF(pdf.PageCount(), counter.n)
+	span.SetAttributes(attribute.Int("pdf.pages", pdf.PageCount()), attribute.Int64("pdf.bytes", counter.n))
+
 	return nil
 }
 
-// downloadOrgLogos downloads logos of organizations.
+// prepareLogos downloads the logos of copyrights and normalizes them for the layout of
+// opts. It returns them by URL, sized as drawn; missing and broken logos are left out.
+func (m *Manager) prepareLogos(
+	ctx context.Context,
+	copyrights []ByOrganizations,
+	opts pdf_service.Options,
+) map[string]LogoOrganization {
+	box := m.logoBox(opts)
+	logos := make(map[string]LogoOrganization)
+	downloaded := m.downloadOrgLogos(ctx, copyrights)
+
+	for url, logo := range downloaded {
+		normalized, err := NormalizeLogo(ctx, logo.Path, box)
+		if err != nil {
+			logLogoError(ctx, url, err)
+
+			continue
+		}
+
+		imageWidth, imageHeight := ScaleDimensions(
+			float64(normalized.Width), float64(normalized.Height), opts.ImgWidthMax, opts.ImgHeightMax,
+		)
+		logos[url] = LogoOrganization{
+			URL:       url,
+			Path:      normalized.Path,
+			Ext:       normalized.Format,
+			ImageType: normalized.ImageType,
+			Width:     imageWidth,
+			Height:    math.Min(imageHeight, opts.ImgHeightMax),
+		}
+	}
+
+	if report, ok := ctx.Value(logoReportKey{}).(*LogoReport); ok {
+		for _, copyright := range copyrights {
+			for _, org := range copyright.Organizations {
+				if url := org.OrganizationLogoURL; url != "" {
+					_, drawn := logos[url]
+					report.record(url, downloaded[url].Digest, drawn)
+				}
+			}
+		}
+	}
+
+	return logos
+}
+
+// LogoReport records the logos of the PDFs rendered with the context of WithLogoReport.
+type LogoReport struct {
+	mu         sync.Mutex
+	digests    LogoDigests
+	unresolved int
+}
+
+type logoReportKey struct{}
+
+// WithLogoReport returns a context whose PDF renders record their logos in the report.
+func WithLogoReport(ctx context.Context) (context.Context, *LogoReport) {
+	report := &LogoReport{digests: make(LogoDigests)}
+
+	return context.WithValue(ctx, logoReportKey{}, report), report
+}
+
+// Digests returns the logos drawn.
+func (r *LogoReport) Digests() LogoDigests {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return maps.Clone(r.digests)
+}
+
+// Complete reports whether every logo URL was drawn, rather than a placeholder because the
+// logo failed, was rejected, or did not fit the download budget.
+func (r *LogoReport) Complete() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.unresolved == 0
+}
+
+func (r *LogoReport) record(url, digest string, drawn bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if drawn {
+		r.digests[url] = digest
+	} else {
+		r.unresolved++
+	}
+}
+
+// logoBox returns the box logos are drawn in by opts, at the configured DPI and pixel limit.
+func (m *Manager) logoBox(opts pdf_service.Options) LogoBox {
+	box := LogoBoxFor(opts, m.Config.Logos.DPI)
+	box.MaxPixels = m.Config.Logos.MaxPixels
+
+	return box
+}
+
+// logoFor returns the logo drawn for org: its own, or a square placeholder as tall as the
+// largest logos, so that cards keep consistent heights.
+func logoFor(
+	logos map[string]LogoOrganization,
+	org OrganizationsForCopyright,
+	opts pdf_service.Options,
+) LogoOrganization {
+	if logo, ok := logos[org.OrganizationLogoURL]; ok {
+		return logo
+	}
+
+	return LogoOrganization{Placeholder: true, Width: opts.ImgHeightMax, Height: opts.ImgHeightMax}
+}
+
+// MarkLogoPlaceholders returns copyrights with LogoPlaceholder set on the organizations that
+// have no logo URL, or whose logo cannot be downloaded or decoded, and so are shown with a
+// placeholder in PDFs. Logos are prepared as for a PDF, through the logo cache, so a logo
+// not cached yet is fetched rather than marked, and the next PDF reuses it.
+func (m *Manager) MarkLogoPlaceholders(ctx context.Context, copyrights []ByOrganizations) []ByOrganizations {
+	logos := m.prepareLogos(ctx, copyrights, pdf_service.Configuration())
+	marked := make([]ByOrganizations, len(copyrights))
+
+	for i, copyright := range copyrights {
+		copyright.Organizations = slices.Clone(copyright.Organizations)
+		for j, org := range copyright.Organizations {
+			_, drawn := logos[org.OrganizationLogoURL]
+			copyright.Organizations[j].LogoPlaceholder = !drawn
+		}
+
+		marked[i] = copyright
+	}
+
+	return marked
+}
+
+// downloadOrgLogos fetches the logos of organizations through the logo cache.
 // It takes in a slice of copyrights, each containing information about an organization
 // including its logo URL. The function returns a map where the keys are logo URLs and the
-// values are the file paths where the logos were downloaded to.
-func downloadOrgLogos(ctx context.Context, copyrights []ByOrganizations) map[string]string {
+// values are the cached logos.
+func (m *Manager) downloadOrgLogos(ctx context.Context, copyrights []ByOrganizations) map[string]logo_service.Logo {
+	ctx, span := tracing_service.Tracer().Start(ctx, "downloadOrgLogos")
+	defer span.End()
+
 	type result struct {
-		url, path string
-		err       error
+		url  string
+		logo logo_service.Logo
+		err  error
 	}
-	tmpDir := filepath.Join(LocalTempFolder, CopyrightFolder)
-	// Ensure the base tmpDir exists
-	if err := os.MkdirAll(tmpDir, DirPerm); err != nil {
-		slog.Error("failed to create tmpDir", "path", tmpDir, "err", err)
 
-		return nil
-	}
+	logos := m.logos()
+	// The budget bounds what the logos of this PDF may download in total.
+	ctx = logo_service.WithBudget(ctx, int64(m.Config.Logos.Budget))
 
-	// Collect all distinct URLs
+	// Collect all distinct URLs; repeated references reuse the same download.
 	urlSet := make(map[string]struct{})
+	references := 0
 
 	for _, cr := range copyrights {
 		for _, org := range cr.Organizations {
+			if org.OrganizationLogoURL == "" {
+				continue
+			}
+
 			urlSet[org.OrganizationLogoURL] = struct{}{}
+			references++
 		}
 	}
 	urls := make([]string, 0, len(urlSet))
@@ -432,38 +641,80 @@ func downloadOrgLogos(ctx context.Context, copyrights []ByOrganizations) map[str
 	}
 
 	channel := make(chan result, len(urls))
-	sem := make(chan struct{}, MaxConcurrentDownloads)
+	sem := make(chan struct{}, m.Config.MaxConcurrentDownloads)
 
 	for _, url := range urls {
 		sem <- struct{}{}
 		go func(url string) {
 			defer func() { <-sem }()
 
-			dest := filepath.Join(tmpDir, path.Base(url))
-
-			err := DownloadImage(ctx, url, dest)
-
+			logo, err := logos.Get(ctx, url)
 			if err != nil {
-				slog.Warn("download failed", "url", url, "err", err)
-				channel <- result{url, "", err}
-			} else {
-				channel <- result{url, dest, nil}
+				logLogoError(ctx, url, err)
 			}
+
+			channel <- result{url, logo, err}
 		}(url)
 	}
 
-	downloaded := make(map[string]string, len(urls))
+	downloaded := make(map[string]logo_service.Logo, len(urls))
+	fetched := 0
 	// Wait for all goroutines
 	for range urls {
 		res := <-channel
 		if res.err == nil {
-			downloaded[res.url] = res.path
+			downloaded[res.url] = res.logo
+			if res.logo.Fetched {
+				fetched++
+			}
 		}
 	}
 
+	failed := len(urls) - len(downloaded)
+	span.SetAttributes(
+		attribute.Int("logos.downloaded", fetched),
+		attribute.Int("logos.cached", len(downloaded)-fetched),
+		attribute.Int("logos.failed", failed),
+	)
+
+	recorder := metrics_service.Default()
+	recorder.AddLogos(metrics_service.LogoDownloaded, fetched)
+	recorder.AddLogos(metrics_service.LogoFailed, failed)
+	recorder.AddLogos(metrics_service.LogoCached, references-fetched-failed)
+
 	return downloaded
 }
 
+// logLogoError logs a logo that cannot be used, distinguishing logos rejected by policy or
+// for their size, which retrying will not fix, by the reason of the rejection.
+func logLogoError(ctx context.Context, url string, err error) {
+	var rejected *logo_service.RejectedError
+
+	switch {
+	case errors.As(err, &rejected):
+		util.LoggerFrom(ctx).Warn("Logo rejected", "url", url, "reason", rejected.Reason.Error(), "error", err)
+	case errors.Is(err, ErrTooManyPixels):
+		util.LoggerFrom(ctx).Warn("Logo rejected", "url", url, "reason", ErrTooManyPixels.Error(), "error", err)
+	case errors.Is(err, ErrUnsupportedImage), errors.Is(err, ErrEmptyImage):
+		util.LoggerFrom(ctx).Warn("Logo cannot be drawn", "url", url, "error", err)
+	default:
+		util.LoggerFrom(ctx).Warn("download failed", "url", url, "err", err)
+	}
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
 func placeCard(pdf *fpdf.Fpdf, opts pdf_service.Options,
 	copyright ByOrganizations,
 	pathOrgLogo map[string]LogoOrganization,
@@ -492,7 +743,7 @@ func placeCard(pdf *fpdf.Fpdf, opts pdf_service.Options,
 
 	// Draw Organization information (Logo, name, etc.)
 	for _, org := range copyright.Organizations {
-		orgInfoHeight := placeOrgInfo(pdf, opts, copyright, org, pathOrgLogo[org.OrganizationLogoURL], axisX, currentY)
+		orgInfoHeight := placeOrgInfo(pdf, opts, copyright, org, logoFor(pathOrgLogo, org, opts), axisX, currentY)
 		currentY += orgInfoHeight
 	}
 
@@ -517,11 +768,13 @@ func placeOrgInfo(pdf *fpdf.Fpdf, opts pdf_service.Options,
 	var opt fpdf.ImageOptions
 	opt.ReadDpi = true
 
-	opt.ImageType = strings.TrimPrefix(orgLogo.Ext, ".")
+	opt.ImageType = orgLogo.ImageType
 
 	currentY := axisY
 
-	if orgLogo.HasValidPath() {
+	if orgLogo.Placeholder {
+		drawPlaceholder(pdf, opts, copyrightOrg, axisX+opts.CardPadding, currentY, orgLogo.Height)
+	} else if orgLogo.HasValidPath() {
 		pdf.ImageOptions(orgLogo.Path, axisX+opts.CardPadding, currentY, orgLogo.Width, orgLogo.Height, false, opt, 0, "")
 	}
 
@@ -1,11 +1,17 @@
 package copyright_test
 
 import (
+	"bytes"
+	"image"
+	"image/png"
 	"io"
 	"testing"
+	"time"
 
+	"biblebrain-services/config"
 	connection_service "biblebrain-services/service/connection"
 	copyright_service "biblebrain-services/service/copyright"
+	logo_service "biblebrain-services/service/logo"
 
 	"github.com/stretchr/testify/require"
 )
@@ -14,11 +20,15 @@ import (
 // produces a valid PDF stream for a real database connection.
 func TestStreamCopyrightIntegration(t *testing.T) {
 	t.Parallel()
-	sqlCon := connection_service.GetBibleBrainDB(t.Context())
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	sqlCon, err := connection_service.GetBibleBrainDB(t.Context(), cfg)
+	require.NoError(t, err)
 
 	defer sqlCon.Close()
 
-	mgr := copyright_service.New(sqlCon)
+	mgr := copyright_service.New(sqlCon, cfg.Copyright)
 
 	// Define a package with known product codes
 	pkg := copyright_service.Package{
@@ -34,7 +44,7 @@ func TestStreamCopyrightIntegration(t *testing.T) {
 	// Stream the PDF (audio=true)
 	copyrights, err := mgr.GetCopyrightBy(t.Context(), pkg.Products, "audio")
 	require.NoError(t, err)
-	reader, err := mgr.StreamCopyright(t.Context(), copyrights, "audio")
+	reader, err := mgr.StreamCopyright(t.Context(), copyrights, "audio", copyright_service.Layout{})
 	require.NoError(t, err)
 	defer reader.Close()
 
@@ -55,11 +65,15 @@ func TestStreamCopyrightIntegration(t *testing.T) {
 // records from the database and includes valid organization info.
 func TestGetCopyrightByIntegration(t *testing.T) {
 	t.Parallel()
-	sqlCon := connection_service.GetBibleBrainDB(t.Context())
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	sqlCon, err := connection_service.GetBibleBrainDB(t.Context(), cfg)
+	require.NoError(t, err)
 
 	defer sqlCon.Close()
 
-	mgr := copyright_service.New(sqlCon)
+	mgr := copyright_service.New(sqlCon, cfg.Copyright)
 
 	codes := []string{"P1PUI/LAN", "N2SWA/HNV", "N2POR/BSP", "N2ENG/NIV", "P1KEB/CIE"}
 	results, err := mgr.GetCopyrightBy(t.Context(), codes, "audio")
@@ -71,4 +85,129 @@ func TestGetCopyrightByIntegration(t *testing.T) {
 		require.Contains(t, codes, rec.ProductCode, "unexpected product code")
 		require.NotEmpty(t, rec.Organizations, "expected at least one organization")
 	}
+
+	version, err := mgr.GetVersion(t.Context(), codes, "audio")
+	require.NoError(t, err)
+	require.False(t, version.IsEmpty())
+	require.False(t, version.UpdatedAt.IsZero())
+}
+
+// TestCacheKey verifies that the cache key ignores the order and duplicates of product codes
+// but changes with the layout, the data version and the logos.
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	version := copyright_service.Version{Rows: 3, UpdatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	key := copyright_service.CacheKey([]string{"N2ENG/NIV", "P1PUI/LAN"}, "audio", "pdf", 6414,
+		copyright_service.Layout{}, version, nil)
+
+	require.Len(t, key, 64)
+	require.Equal(t, key, copyright_service.CacheKey([]string{"P1PUI/LAN", " N2ENG/NIV", "P1PUI/LAN"}, "audio", "pdf",
+		6414, copyright_service.Layout{GridSize: copyright_service.CopyrightGridAudio}, version, nil))
+
+	require.NotEqual(t, key, copyright_service.CacheKey([]string{"N2ENG/NIV", "P1PUI/LAN"}, "audio", "pdf", 6414,
+		copyright_service.Layout{PageSize: "Letter"}, version, nil))
+
+	require.NotEqual(t, key, copyright_service.CacheKey([]string{"N2ENG/NIV", "P1PUI/LAN"}, "audio", "pdf", 6414,
+		copyright_service.Layout{}, version, copyright_service.LogoDigests{"https://cdn.example.com/a.png": "ab"}))
+
+	version.UpdatedAt = version.UpdatedAt.Add(time.Second)
+	require.NotEqual(t, key, copyright_service.CacheKey([]string{"N2ENG/NIV", "P1PUI/LAN"}, "audio", "pdf", 6414,
+		copyright_service.Layout{}, version, nil))
+}
+
+func TestETag(t *testing.T) {
+	t.Parallel()
+
+	niv := copyright_service.ByOrganizations{
+		ProductCode: "N2ENG/NIV",
+		Copyright:   "© Biblica",
+		Organizations: []copyright_service.OrganizationsForCopyright{
+			{OrganizationID: 2, OrganizationName: "Biblica"},
+			{OrganizationID: 1, OrganizationName: "Hosanna"},
+		},
+	}
+	lan := copyright_service.ByOrganizations{ProductCode: "P1PUI/LAN", Copyright: "© Wycliffe"}
+
+	etag := copyright_service.ETag([]copyright_service.ByOrganizations{niv, lan}, nil, "pdf", "audio",
+		copyright_service.Layout{})
+	require.Regexp(t, `^W/"[0-9a-f]{64}"$`, etag)
+
+	reordered := niv
+	reordered.Organizations = []copyright_service.OrganizationsForCopyright{niv.Organizations[1], niv.Organizations[0]}
+	require.Equal(t, etag, copyright_service.ETag([]copyright_service.ByOrganizations{lan, reordered}, nil, "pdf", "audio",
+		copyright_service.Layout{}))
+	require.Equal(t, "Biblica", niv.Organizations[0].OrganizationName, "ETag must not reorder its input")
+
+	require.NotEqual(t, etag, copyright_service.ETag([]copyright_service.ByOrganizations{niv, lan}, nil, "json", "audio",
+		copyright_service.Layout{}))
+
+	lan.Copyright = "© Wycliffe 2025"
+	require.NotEqual(t, etag, copyright_service.ETag([]copyright_service.ByOrganizations{niv, lan}, nil, "pdf", "audio",
+		copyright_service.Layout{}))
+}
+
+// TestProducePdfCopyrightOffline verifies that a PDF is generated with logos from a fake
+// fetcher, without a database or network.
+func TestProducePdfCopyrightOffline(t *testing.T) {
+	t.Parallel()
+
+	const (
+		pngURL = "https://cdn.example.com/a/logo.png"
+		svgURL = "s3://logos/b/logo.svg"
+		// The extension and content type of this WebP say nothing of its format.
+		webpURL = "https://cdn.example.com/c/logo"
+	)
+
+	var pngLogo bytes.Buffer
+	require.NoError(t, png.Encode(&pngLogo, image.NewRGBA(image.Rect(0, 0, 40, 20))))
+
+	svgLogo := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 40 20">` +
+		`<rect width="40" height="20" fill="#336699"/></svg>`
+
+	fake := logo_service.NewFake(map[string]logo_service.Response{
+		pngURL:  {Body: pngLogo.Bytes(), ContentType: "image/png"},
+		svgURL:  {Body: []byte(svgLogo), ContentType: "image/svg+xml"},
+		webpURL: {Body: []byte(webpLogo), ContentType: "application/octet-stream"},
+	})
+	dir := t.TempDir()
+	fetcher := logo_service.Schemes{"https": fake, "s3": fake}
+
+	mgr := &copyright_service.Manager{
+		Config: config.Default().Copyright,
+		Logos:  logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, time.Hour, fetcher),
+	}
+
+	copyrights := []copyright_service.ByOrganizations{
+		{
+			ProductCode: "N2ENG/NIV",
+			Copyright:   "© Biblica",
+			Organizations: []copyright_service.OrganizationsForCopyright{
+				{OrganizationID: 1, OrganizationName: "Biblica", OrganizationLogoURL: pngURL},
+			},
+		},
+		{
+			ProductCode: "P1PUI/LAN",
+			Copyright:   "© Wycliffe",
+			Organizations: []copyright_service.OrganizationsForCopyright{
+				{OrganizationID: 2, OrganizationName: "Wycliffe", OrganizationLogoURL: svgURL},
+				{OrganizationID: 1, OrganizationName: "Biblica", OrganizationLogoURL: pngURL},
+				{OrganizationID: 3, OrganizationName: "SIL", OrganizationLogoURL: webpURL},
+			},
+		},
+	}
+
+	ctx, drawn := copyright_service.WithLogoReport(t.Context())
+
+	var out bytes.Buffer
+	require.NoError(t, mgr.ProducePdfCopyright(ctx, &out, copyrights,
+		copyright_service.Layout{}.ForMode(copyright_service.ModeAudio)))
+
+	require.True(t, bytes.HasPrefix(out.Bytes(), []byte("%PDF-")))
+	require.True(t, drawn.Complete())
+	require.Equal(t, mgr.CachedLogoDigests(t.Context(), copyrights), drawn.Digests())
+	require.Len(t, drawn.Digests(), 3)
+	require.Equal(t, 1, fake.Requests(pngURL))
+	require.Equal(t, 1, fake.Requests(svgURL))
+	require.Equal(t, 1, fake.Requests(webpURL))
 }
@@ -0,0 +1,30 @@
+package copyright
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// ReadCopyrights decodes copyrights saved as the JSON output of the service, such as to render
+// them without a database. With productCodes, only the copyrights of those products are kept;
+// ErrProductsNotFound is returned when none is left.
+func ReadCopyrights(reader io.Reader, productCodes []string) ([]ByOrganizations, error) {
+	var copyrights []ByOrganizations
+	if err := json.NewDecoder(reader).Decode(&copyrights); err != nil {
+		return nil, fmt.Errorf("decoding copyrights: %w", err)
+	}
+
+	if len(productCodes) > 0 {
+		copyrights = slices.DeleteFunc(copyrights, func(copyright ByOrganizations) bool {
+			return !slices.Contains(productCodes, copyright.ProductCode)
+		})
+	}
+
+	if len(copyrights) == 0 {
+		return nil, ErrProductsNotFound
+	}
+
+	return copyrights, nil
+}
@@ -0,0 +1,38 @@
+package copyright_test
+
+import (
+	"strings"
+	"testing"
+
+	copyright_service "biblebrain-services/service/copyright"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadCopyrights verifies that the JSON output of the service is read back, optionally
+// restricted to some products.
+func TestReadCopyrights(t *testing.T) {
+	t.Parallel()
+
+	const saved = `[
+		{"productCode": "N2ENG/NIV", "copyright": "© Biblica", "copyrightDate": "2011",
+		 "organizations": [{"organizationId": 1, "organizationName": "Biblica", "logoPlaceholder": true}]},
+		{"productCode": "P1PUI/LAN", "copyright": "© Wycliffe", "organizations": []}
+	]`
+
+	copyrights, err := copyright_service.ReadCopyrights(strings.NewReader(saved), nil)
+	require.NoError(t, err)
+	require.Len(t, copyrights, 2)
+	require.Equal(t, "Biblica", copyrights[0].Organizations[0].OrganizationName)
+
+	copyrights, err = copyright_service.ReadCopyrights(strings.NewReader(saved), []string{"P1PUI/LAN", "N1XYZ/ABC"})
+	require.NoError(t, err)
+	require.Len(t, copyrights, 1)
+	require.Equal(t, "P1PUI/LAN", copyrights[0].ProductCode)
+
+	_, err = copyright_service.ReadCopyrights(strings.NewReader(saved), []string{"N1XYZ/ABC"})
+	require.ErrorIs(t, err, copyright_service.ErrProductsNotFound)
+
+	_, err = copyright_service.ReadCopyrights(strings.NewReader(`{"productCode": "N2ENG/NIV"}`), nil)
+	require.Error(t, err)
+}
@@ -1,135 +1,493 @@
 package copyright
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Register the GIF decoder for image.Decode.
+	"image/jpeg"
 	"image/png"
-	"io"
-	"log/slog"
 	"math"
-	"net/http"
 	"os"
-	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
+
+	logo_service "biblebrain-services/service/logo"
+	pdf_service "biblebrain-services/service/pdf"
+	tracing_service "biblebrain-services/service/tracing"
 
 	"github.com/srwiley/oksvg"
 	"github.com/srwiley/rasterx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder for image.Decode.
 )
 
 const (
 	// Supported image formats.
-	PNGFormat = "png"
-	SVGFormat = "svg"
+	PNGFormat  = "png"
+	JPEGFormat = "jpg"
+	GIFFormat  = "gif"
+	WebPFormat = "webp"
+	SVGFormat  = "svg"
 )
 
-// ErrDownloadStatus indicates a non-OK HTTP status code during image download.
-var ErrDownloadStatus = errors.New("image download returned non-OK status")
+// DefaultDPI is the print resolution of logos when none is configured.
+const DefaultDPI = 300
+
+// DefaultMaxPixels bounds the pixels of logos when no limit is configured: 32 MB decoded.
+const DefaultMaxPixels = 8_000_000
+
+// jpegQuality is the quality logos that were JPEG are re-encoded with.
+const jpegQuality = 90
+
+// ErrUnsupportedImage indicates a logo whose content is not in a supported format.
+var ErrUnsupportedImage = errors.New("unsupported logo image format")
+
+// ErrEmptyImage indicates a logo without pixels, such as an SVG without a view box.
+var ErrEmptyImage = errors.New("logo image is empty")
+
+// ErrTooManyPixels indicates a logo larger than LogoBox.MaxPixels, which is not decoded.
+var ErrTooManyPixels = errors.New("logo image has too many pixels")
+
+// NormalizedLogo is a logo re-encoded for the PDF renderer.
+type NormalizedLogo struct {
+	// Path is the file holding the normalized logo.
+	Path string
+	// Format is the format detected from the content of the original logo.
+	Format string
+	// ImageType is the format of Path: PNGFormat, or JPEGFormat for logos that were JPEG.
+	ImageType string
+	Width     int
+	Height    int
+}
+
+// LogoBox is the box logos are drawn in, in pixels at DPI.
+type LogoBox struct {
+	Width  int
+	Height int
+	DPI    int
+	// MaxPixels bounds the logos decoded; zero selects DefaultMaxPixels.
+	MaxPixels int
+}
+
+// maxPixels returns the pixel limit of b.
+func (b LogoBox) maxPixels() int {
+	if b.MaxPixels <= 0 {
+		return DefaultMaxPixels
+	}
+
+	return b.MaxPixels
+}
+
+// checkPixels returns ErrTooManyPixels when width by height exceeds the limit of b.
+func (b LogoBox) checkPixels(width, height int) error {
+	if int64(width)*int64(height) > int64(b.maxPixels()) {
+		return fmt.Errorf("%w: %dx%d, at most %d", ErrTooManyPixels, width, height, b.maxPixels())
+	}
+
+	return nil
+}
 
-// SVGToPNG reads an SVG file from svgPath, renders it to a PNG image, and
-// writes the result to a new file with a .png extension. It returns the path
-// to the generated PNG file or an error.
-func SVGToPNG(svgPath string) (string, error) {
-	infile, err := os.Open(svgPath)
+// LogoBoxFor returns the box logos are drawn in by opts, at dpi, or DefaultDPI when dpi is
+// not positive.
+func LogoBoxFor(opts pdf_service.Options, dpi int) LogoBox {
+	if dpi <= 0 {
+		dpi = DefaultDPI
+	}
+
+	// Points per page unit, as fpdf defines them.
+	pointsPerUnit := map[string]float64{"pt": 1, "mm": 72 / 25.4, "cm": 72 / 2.54, "in": 72}[opts.PageUnits]
+	pixelsPerUnit := pointsPerUnit / 72 * float64(dpi)
+
+	return LogoBox{
+		Width:  int(math.Ceil(opts.ImgWidthMax * pixelsPerUnit)),
+		Height: int(math.Ceil(opts.ImgHeightMax * pixelsPerUnit)),
+		DPI:    dpi,
+	}
+}
+
+// NormalizeLogo turns the logo at logoPath, whatever its extension, into a PNG or JPEG the
+// PDF renderer reads reliably. The format is detected from the content; PNG, JPEG, GIF and
+// WebP are decoded and scaled down to fit box, SVG is rasterized at the size it fills box
+// at, then the logo is flattened onto white and re-encoded tagged with the DPI of box. JPEG
+// stays JPEG, everything else becomes PNG.
+//
+// The result is written next to the logo, under a name depending on box, and reused when it
+// already exists: cached logos are content-addressed, so it never goes stale.
+func NormalizeLogo(ctx context.Context, logoPath string, box LogoBox) (logo NormalizedLogo, err error) {
+	_, span := tracing_service.Tracer().Start(ctx, "NormalizeLogo",
+		trace.WithAttributes(attribute.String("image", logoPath)),
+	)
+	defer func() { tracing_service.End(span, err) }()
+
+	data, err := os.ReadFile(logoPath)
 	if err != nil {
-		return "", fmt.Errorf("open SVG %q: %w", svgPath, err)
+		return NormalizedLogo{}, fmt.Errorf("read logo %q: %w", logoPath, err)
 	}
-	defer infile.Close()
 
-	icon, err := oksvg.ReadIconStream(infile)
+	logo.Format, err = detectFormat(data)
 	if err != nil {
-		return "", fmt.Errorf("parse SVG %q: %w", svgPath, err)
+		return NormalizedLogo{}, fmt.Errorf("logo %q: %w", logoPath, err)
 	}
 
-	w := int(icon.ViewBox.W)
-	h := int(icon.ViewBox.H)
-	icon.SetTarget(0, 0, float64(w), float64(h))
-	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
-	icon.Draw(rasterx.NewDasher(w, h, rasterx.NewScannerGV(w, h, rgba, rgba.Bounds())), 1)
+	logo.ImageType = PNGFormat
+	if logo.Format == JPEGFormat {
+		logo.ImageType = JPEGFormat
+	}
+
+	logo.Path = fmt.Sprintf("%s-%dx%d@%d.%s", logoPath, box.Width, box.Height, box.DPI, logo.ImageType)
+	span.SetAttributes(attribute.String("format", logo.Format))
+
+	if cfg, err := decodeConfig(logo.Path); err == nil {
+		logo.Width, logo.Height = cfg.Width, cfg.Height
+
+		return logo, nil
+	}
+
+	img, err := decode(data, logo.Format, box)
+	if err != nil {
+		return NormalizedLogo{}, fmt.Errorf("decode logo %q: %w", logoPath, err)
+	}
 
-	base := filepath.Base(svgPath)
-	ext := path.Ext(base)
-	name := strings.TrimSuffix(base, ext)
-	pngPath := filepath.Join(filepath.Dir(svgPath), name+"."+PNGFormat)
+	flat := flatten(img, box.Width, box.Height)
+	logo.Width, logo.Height = flat.Bounds().Dx(), flat.Bounds().Dy()
 
-	out, err := os.Create(pngPath)
+	encoded, err := encode(flat, logo.ImageType, box.DPI)
 	if err != nil {
-		return "", fmt.Errorf("create PNG %q: %w", pngPath, err)
+		return NormalizedLogo{}, fmt.Errorf("encode logo %q: %w", logoPath, err)
 	}
-	defer out.Close()
 
-	if err := png.Encode(out, rgba); err != nil {
-		return "", fmt.Errorf("encode PNG %q: %w", pngPath, err)
+	if err := writeAtomic(logo.Path, encoded); err != nil {
+		return NormalizedLogo{}, err
 	}
 
-	return pngPath, nil
+	return logo, nil
 }
 
-// GetImageDimensions opens the image file at imagePath and returns its width and height
-// in float64. Returns an error if opening or decoding fails.
-func GetImageDimensions(imagePath string) (float64, float64, error) {
-	ifile, err := os.Open(imagePath)
+// detectFormat returns the format of an image from its content.
+func detectFormat(data []byte) (string, error) {
+	switch mediaType := logo_service.Sniff(data); mediaType {
+	case "image/png":
+		return PNGFormat, nil
+	case "image/jpeg":
+		return JPEGFormat, nil
+	case "image/gif":
+		return GIFFormat, nil
+	case "image/webp":
+		return WebPFormat, nil
+	case "image/svg+xml":
+		return SVGFormat, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedImage, mediaType)
+	}
+}
+
+// decode decodes an image of the given format; the first frame of animated images. SVG is
+// rasterized to fit box.
+func decode(data []byte, format string, box LogoBox) (image.Image, error) {
+	if format == SVGFormat {
+		return rasterizeSVG(data, box)
+	}
+
+	// The header tells the size before anything is allocated for the pixels.
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
-		return 0, 0, fmt.Errorf("open image %q: %w", imagePath, err)
+		return nil, fmt.Errorf("decoding %s: %w", format, err)
+	}
+
+	if err := box.checkPixels(cfg.Width, cfg.Height); err != nil {
+		return nil, err
 	}
-	defer ifile.Close()
 
-	cfg, _, err := image.DecodeConfig(ifile)
+	img, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
-		return 0, 0, fmt.Errorf("decode image %q: %w", imagePath, err)
+		return nil, fmt.Errorf("decoding %s: %w", format, err)
 	}
 
-	return float64(cfg.Width), float64(cfg.Height), nil
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	return img, nil
 }
 
-// ScaleDimensions calculates new dimensions that fit within maxWidth and maxHeight
-// while preserving aspect ratio.
-func ScaleDimensions(width, height, maxWidth, maxHeight float64) (float64, float64) {
-	scaleW := maxWidth / width
-	scaleH := maxHeight / height
-	scale := math.Min(scaleW, scaleH)
+// rasterizeSVG renders an SVG at the largest size fitting box, as the logo is drawn, rather
+// than at the size of its view box, which would print blurry; at the size of the view box
+// when box has no size, within the pixel limit of box either way.
+func rasterizeSVG(data []byte, box LogoBox) (image.Image, error) {
+	data, err := withViewBox(data)
+	if err != nil {
+		return nil, err
+	}
 
-	return width * scale, height * scale
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing SVG: %w", err)
+	}
+
+	if icon.ViewBox.W <= 0 || icon.ViewBox.H <= 0 {
+		return nil, ErrEmptyImage
+	}
+
+	width, height := icon.ViewBox.W, icon.ViewBox.H
+	if box.Width > 0 && box.Height > 0 {
+		width, height = ScaleDimensions(width, height, float64(box.Width), float64(box.Height))
+	}
+
+	// Checked before converting, since view boxes can be larger than any int.
+	if width*height > float64(box.maxPixels()) {
+		return nil, fmt.Errorf("%w: %.0fx%.0f, at most %d", ErrTooManyPixels, width, height, box.maxPixels())
+	}
+
+	w, h := max(1, int(math.Round(width))), max(1, int(math.Round(height)))
+	icon.SetTarget(0, 0, float64(w), float64(h))
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	icon.Draw(rasterx.NewDasher(w, h, rasterx.NewScannerGV(w, h, rgba, rgba.Bounds())), 1)
+
+	return rgba, nil
+}
+
+// sizeAttrPattern matches the width and height attributes of an element.
+var sizeAttrPattern = regexp.MustCompile(`\s(width|height)\s*=\s*("[^"]*"|'[^']*')`)
+
+// withViewBox rewrites the root element of an SVG without a view box to have one, derived
+// from its width and height, and drops those: oksvg only reads them as unitless numbers,
+// failing on lengths such as "10mm" or "100%", and the size is given by the box anyway.
+func withViewBox(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		start := decoder.InputOffset()
+
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("parsing SVG: %w", err)
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if element.Name.Local != "svg" {
+			return data, nil
+		}
+
+		var width, height float64
+
+		hasViewBox := false
+
+		for _, attr := range element.Attr {
+			switch attr.Name.Local {
+			case "viewBox":
+				hasViewBox = true
+			case "width":
+				width = svgLength(attr.Value)
+			case "height":
+				height = svgLength(attr.Value)
+			}
+		}
+
+		end := decoder.InputOffset()
+		tag := sizeAttrPattern.ReplaceAll(data[start:end], nil)
+
+		if !hasViewBox {
+			if width <= 0 || height <= 0 {
+				return nil, ErrEmptyImage
+			}
+
+			closing := len(tag) - len(">")
+			if bytes.HasSuffix(tag, []byte("/>")) {
+				closing = len(tag) - len("/>")
+			}
+
+			viewBox := fmt.Sprintf(` viewBox="0 0 %g %g"`, width, height)
+			tag = slices.Concat(tag[:closing], []byte(viewBox), tag[closing:])
+		}
+
+		return slices.Concat(data[:start], tag, data[end:]), nil
+	}
 }
 
-// DownloadImage fetches the resource at urlStr and writes it to destPath,
-// creating any missing directories. Returns a wrapped error on failure.
-func DownloadImage(ctx context.Context, urlStr, destPath string) error {
-	dir := filepath.Dir(destPath)
-	if err := os.MkdirAll(dir, DirPerm); err != nil {
-		return fmt.Errorf("create directory %q: %w", dir, err)
+// svgLength converts an absolute SVG length, such as "120", "120px" or "30mm", to user units
+// at 96 per inch. Relative lengths, such as "100%", are 0.
+func svgLength(value string) float64 {
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"px", 1}, {"pt", 96.0 / 72}, {"pc", 16}, {"mm", 96 / 25.4}, {"cm", 96 / 2.54}, {"in", 96},
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
-	if err != nil {
-		return fmt.Errorf("create request for %q: %w", urlStr, err)
+	value = strings.TrimSpace(value)
+	scale := 1.0
+
+	for _, unit := range units {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, scale = number, unit.scale
+
+			break
+		}
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("download %q: %w", urlStr, err)
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || number <= 0 {
+		return 0
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("download failed", "url", urlStr, "status", resp.StatusCode)
+	return number * scale
+}
+
+// flatten draws img onto an opaque white image, scaled down to fit maxWidth by maxHeight
+// while preserving its aspect ratio. Images already fitting keep their size.
+func flatten(img image.Image, maxWidth, maxHeight int) *image.RGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if maxWidth > 0 && maxHeight > 0 && (width > maxWidth || height > maxHeight) {
+		scaledWidth, scaledHeight := ScaleDimensions(float64(width), float64(height), float64(maxWidth), float64(maxHeight))
+		width, height = max(1, int(math.Round(scaledWidth))), max(1, int(math.Round(scaledHeight)))
+	}
+
+	flat := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(flat, flat.Bounds(), img, bounds, draw.Over, nil)
+
+	return flat
+}
+
+// encode encodes img as imageType, recording dpi as its resolution.
+func encode(img image.Image, imageType string, dpi int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if imageType == JPEGFormat {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("encoding JPEG: %w", err)
+		}
+
+		return withJFIF(buf.Bytes(), dpi), nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encoding PNG: %w", err)
+	}
+
+	return withPHYs(buf.Bytes(), dpi), nil
+}
+
+// withPHYs inserts a pHYs chunk recording dpi after the IHDR chunk of an encoded PNG; fpdf
+// reads it when ReadDpi is set.
+func withPHYs(encoded []byte, dpi int) []byte {
+	// The PNG signature and the IHDR chunk: length, type, 13 bytes of data and CRC.
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+
+	const (
+		inchesPerMeter = 39.3701
+		physLen        = 9 // Two 4-byte densities and the unit.
+	)
+
+	data := make([]byte, physLen)
+	pixelsPerMeter := uint32(math.Round(float64(dpi) * inchesPerMeter))
+	binary.BigEndian.PutUint32(data[0:4], pixelsPerMeter)
+	binary.BigEndian.PutUint32(data[4:8], pixelsPerMeter)
+	data[8] = 1 // The unit is the meter.
+
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, "pHYs"...)
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
 
-		return fmt.Errorf("%w: %d", ErrDownloadStatus, resp.StatusCode)
+	return append(append(encoded[:ihdrEnd:ihdrEnd], chunk...), encoded[ihdrEnd:]...)
+}
+
+// withJFIF inserts a JFIF APP0 segment recording dpi after the start of image marker of an
+// encoded JPEG, which the standard encoder leaves out.
+func withJFIF(encoded []byte, dpi int) []byte {
+	const soiEnd = 2
+
+	hi, lo := byte(dpi>>8), byte(dpi)
+	segment := []byte{
+		0xff, 0xe0, 0x00, 0x10, // APP0 and the length of the segment.
+		'J', 'F', 'I', 'F', 0x00, 0x01, 0x02, // Identifier and version 1.2.
+		0x01,           // Densities are in dots per inch.
+		hi, lo, hi, lo, // Horizontal and vertical densities.
+		0x00, 0x00, // No thumbnail.
 	}
 
-	out, err := os.Create(destPath)
+	return append(append(encoded[:soiEnd:soiEnd], segment...), encoded[soiEnd:]...)
+}
+
+// writeAtomic writes a normalized logo under a temporary name and renames it, since
+// concurrent requests may normalize the same cached logo and none may read a partial file.
+func writeAtomic(name string, data []byte) error {
+	out, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
 	if err != nil {
-		return fmt.Errorf("create file %q: %w", destPath, err)
+		return fmt.Errorf("create logo %q: %w", name, err)
+	}
+	defer os.Remove(out.Name())
+
+	if _, err := out.Write(data); err != nil {
+		out.Close()
+
+		return fmt.Errorf("write logo %q: %w", name, err)
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("write logo %q: %w", name, err)
 	}
-	defer out.Close()
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		return fmt.Errorf("write to %q: %w", destPath, err)
+	if err := os.Rename(out.Name(), name); err != nil {
+		return fmt.Errorf("write logo %q: %w", name, err)
 	}
 
 	return nil
 }
+
+// decodeConfig returns the dimensions of the image file at imagePath.
+func decodeConfig(imagePath string) (image.Config, error) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("open image %q: %w", imagePath, err)
+	}
+	defer file.Close()
+
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("decode image %q: %w", imagePath, err)
+	}
+
+	return cfg, nil
+}
+
+// GetImageDimensions opens the image file at imagePath and returns its width and height
+// in float64. Returns an error if opening or decoding fails.
+func GetImageDimensions(imagePath string) (float64, float64, error) {
+	cfg, err := decodeConfig(imagePath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return float64(cfg.Width), float64(cfg.Height), nil
+}
+
+// ScaleDimensions calculates new dimensions that fit within maxWidth and maxHeight
+// while preserving aspect ratio.
+func ScaleDimensions(width, height, maxWidth, maxHeight float64) (float64, float64) {
+	scaleW := maxWidth / width
+	scaleH := maxHeight / height
+	scale := math.Min(scaleW, scaleH)
+
+	return width * scale, height * scale
+}
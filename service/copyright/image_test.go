@@ -0,0 +1,151 @@
+package copyright_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	copyright_service "biblebrain-services/service/copyright"
+	pdf_service "biblebrain-services/service/pdf"
+
+	"github.com/stretchr/testify/require"
+)
+
+// webpLogo is a transparent 1x1 lossless WebP.
+const webpLogo = "RIFF\x1a\x00\x00\x00WEBPVP8L\x0d\x00\x00\x00\x2f\x00\x00\x00\x10\x07\x10\x11\x11\x88\x88\xfe\x07\x00"
+
+func writeLogo(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	logoPath := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(logoPath, data, 0o600))
+
+	return logoPath
+}
+
+// TestNormalizeLogo verifies that logos are recognized by content, flattened, scaled down
+// to the box and re-encoded with their resolution.
+func TestNormalizeLogo(t *testing.T) {
+	t.Parallel()
+
+	box := copyright_service.LogoBox{Width: 100, Height: 100, DPI: 300}
+
+	// A WebP named like a PNG becomes a PNG on white.
+	logo, err := copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo.png", []byte(webpLogo)), box)
+	require.NoError(t, err)
+	require.Equal(t, copyright_service.WebPFormat, logo.Format)
+	require.Equal(t, copyright_service.PNGFormat, logo.ImageType)
+
+	data, err := os.ReadFile(logo.Path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "pHYs")
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, color.RGBAModel.Convert(color.White), color.RGBAModel.Convert(img.At(0, 0)))
+
+	// A wide JPEG without extension stays JPEG, scaled down to the box.
+	var wide bytes.Buffer
+	require.NoError(t, jpeg.Encode(&wide, image.NewRGBA(image.Rect(0, 0, 2000, 100)), nil))
+
+	logo, err = copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo", wide.Bytes()), box)
+	require.NoError(t, err)
+	require.Equal(t, copyright_service.JPEGFormat, logo.ImageType)
+	require.Equal(t, [2]int{100, 5}, [2]int{logo.Width, logo.Height})
+
+	data, err = os.ReadFile(logo.Path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "JFIF")
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, 100, cfg.Width)
+
+	// The normalized logo is reused.
+	again, err := copyright_service.NormalizeLogo(t.Context(), filepath.Join(filepath.Dir(logo.Path), "logo"), box)
+	require.NoError(t, err)
+	require.Equal(t, logo, again)
+
+	_, err = copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo.png", []byte("<html></html>")), box)
+	require.ErrorIs(t, err, copyright_service.ErrUnsupportedImage)
+
+	_, err = copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo.svg", []byte(`<svg></svg>`)), box)
+	require.ErrorIs(t, err, copyright_service.ErrEmptyImage)
+}
+
+// TestNormalizeSVG verifies that SVGs are rasterized at the size they are drawn, not at that
+// of their view box, and that a missing view box is derived from the width and height.
+func TestNormalizeSVG(t *testing.T) {
+	t.Parallel()
+
+	box := copyright_service.LogoBox{Width: 400, Height: 100, DPI: 300}
+
+	for name, svg := range map[string]string{
+		"view box":    `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 40 20" width="100%">`,
+		"size":        `<svg xmlns="http://www.w3.org/2000/svg" width="40" height="20">`,
+		"size in mm":  `<?xml version="1.0"?>` + "\n" + `<svg xmlns="http://www.w3.org/2000/svg" width="4mm" height="2mm">`,
+		"size in pts": `<svg xmlns="http://www.w3.org/2000/svg" height='15pt' width='30pt'>`,
+	} {
+		svg += `<rect width="40" height="20" fill="#336699"/></svg>`
+
+		logo, err := copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo", []byte(svg)), box)
+		require.NoError(t, err, name)
+		require.Equal(t, copyright_service.SVGFormat, logo.Format, name)
+		require.Equal(t, [2]int{200, 100}, [2]int{logo.Width, logo.Height}, name)
+	}
+
+	// The rendering depends on the DPI.
+	logoPath := writeLogo(t, "logo", []byte(`<svg width="40" height="20"></svg>`))
+	low, err := copyright_service.NormalizeLogo(t.Context(), logoPath, box)
+	require.NoError(t, err)
+
+	high, err := copyright_service.NormalizeLogo(t.Context(), logoPath, copyright_service.LogoBox{
+		Width: 800, Height: 200, DPI: 600,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, low.Path, high.Path)
+	require.Equal(t, 400, high.Width)
+
+	_, err = copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo", []byte(`<svg width="100%"></svg>`)), box)
+	require.ErrorIs(t, err, copyright_service.ErrEmptyImage)
+}
+
+func TestLogoBox(t *testing.T) {
+	t.Parallel()
+
+	box := copyright_service.LogoBoxFor(pdf_service.Configuration(), 0)
+	require.Equal(t, copyright_service.LogoBox{Width: 827, Height: 237, DPI: 300}, box)
+
+	box = copyright_service.LogoBoxFor(pdf_service.Configuration(), 150)
+	require.Equal(t, copyright_service.LogoBox{Width: 414, Height: 119, DPI: 150}, box)
+}
+
+// TestNormalizeLogoPixels verifies that logos over the pixel limit are rejected before their
+// pixels are decoded or rasterized.
+func TestNormalizeLogoPixels(t *testing.T) {
+	t.Parallel()
+
+	box := copyright_service.LogoBox{Width: 100, Height: 100, DPI: 300, MaxPixels: 10_000}
+
+	var large bytes.Buffer
+	require.NoError(t, png.Encode(&large, image.NewGray(image.Rect(0, 0, 200, 100))))
+
+	_, err := copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo", large.Bytes()), box)
+	require.ErrorIs(t, err, copyright_service.ErrTooManyPixels)
+
+	var small bytes.Buffer
+	require.NoError(t, png.Encode(&small, image.NewGray(image.Rect(0, 0, 100, 100))))
+
+	_, err = copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo", small.Bytes()), box)
+	require.NoError(t, err)
+
+	// Without a box, an SVG is rasterized at the size of its view box.
+	huge := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 1e12 1e12"></svg>`
+	_, err = copyright_service.NormalizeLogo(t.Context(), writeLogo(t, "logo", []byte(huge)), copyright_service.LogoBox{})
+	require.ErrorIs(t, err, copyright_service.ErrTooManyPixels)
+}
@@ -0,0 +1,120 @@
+package copyright
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	pdf_service "biblebrain-services/service/pdf"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// maxLatin1 is the last rune the core PDF fonts can draw.
+const maxLatin1 = 0xff
+
+// Initials returns the monogram of an organization: the first letters of the first and last
+// significant words of name, skipping lowercase words such as "of" or "the", or the first
+// letter of a single word. Names the core PDF fonts cannot draw, such as in Chinese or
+// Arabic script, fall back to slug.
+func Initials(name, slug string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+
+	significant := make([]string, 0, len(words))
+	for _, word := range words {
+		if !unicode.IsLower([]rune(word)[0]) {
+			significant = append(significant, word)
+		}
+	}
+
+	if len(significant) == 0 {
+		significant = words
+	}
+
+	var initials []rune
+
+	switch len(significant) {
+	case 0:
+	case 1:
+		initials = []rune{[]rune(significant[0])[0]}
+	default:
+		initials = []rune{[]rune(significant[0])[0], []rune(significant[len(significant)-1])[0]}
+	}
+
+	for _, r := range initials {
+		if r > maxLatin1 {
+			if name == slug {
+				return "?"
+			}
+
+			return Initials(slug, slug)
+		}
+	}
+
+	if len(initials) == 0 {
+		return "?"
+	}
+
+	return strings.ToUpper(string(initials))
+}
+
+// placeholderColors are the backgrounds of monograms of organizations without a brand color.
+func placeholderColors() [8][3]int {
+	return [8][3]int{
+		{0x33, 0x66, 0x99}, {0x2e, 0x7d, 0x6b}, {0x8e, 0x44, 0x3d}, {0x6c, 0x4f, 0x8f},
+		{0xb0, 0x6b, 0x1e}, {0x3d, 0x5a, 0x80}, {0x5d, 0x6d, 0x3b}, {0x7a, 0x3e, 0x65},
+	}
+}
+
+// placeholderColor returns the background of the monogram of org: its brand color if valid,
+// or else one of placeholderColors picked by its slug, so it is the same in every PDF.
+func placeholderColor(org OrganizationsForCopyright) (int, int, int) {
+	if digits, ok := strings.CutPrefix(org.OrganizationColor, "#"); ok {
+		if rgb, err := hex.DecodeString(digits); err == nil && len(rgb) == len("rgb") {
+			return int(rgb[0]), int(rgb[1]), int(rgb[2])
+		}
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(org.OrganizationSlug))
+
+	colors := placeholderColors()
+	color := colors[hash.Sum32()%uint32(len(colors))]
+
+	return color[0], color[1], color[2]
+}
+
+// drawPlaceholder draws at x, y a rounded square badge of side size, in the color of org,
+// with its initials in a contrasting color.
+func drawPlaceholder(pdf *fpdf.Fpdf, opts pdf_service.Options, org OrganizationsForCopyright, x, y, size float64) {
+	const (
+		cornerRatio = 0.15
+		textRatio   = 0.4
+		// Backgrounds lighter than this luma get dark initials.
+		lightLuma = 160
+		darkText  = 0x33
+		lightText = 0xff
+	)
+
+	red, green, blue := placeholderColor(org)
+	pdf.SetFillColor(red, green, blue)
+	pdf.RoundedRect(x, y, size, size, size*cornerRatio, "1234", "F")
+
+	text := lightText
+	if 0.299*float64(red)+0.587*float64(green)+0.114*float64(blue) > lightLuma {
+		text = darkText
+	}
+
+	// Font sizes are in points; size is in page units.
+	pdf.SetFont(opts.FontFamily, "B", size*textRatio*pdf.GetConversionRatio())
+	pdf.SetTextColor(text, text, text)
+	pdf.SetXY(x, y)
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.CellFormat(size, size, tr(Initials(org.OrganizationName, org.OrganizationSlug)), "", 0, "CM", false, 0, "")
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFillColor(lightText, lightText, lightText)
+	pdf.SetFont(opts.FontFamily, opts.FontStyle, opts.FontSize)
+}
@@ -0,0 +1,99 @@
+package copyright_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"biblebrain-services/config"
+	copyright_service "biblebrain-services/service/copyright"
+	logo_service "biblebrain-services/service/logo"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitials(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct{ name, slug, want string }{
+		{"Wycliffe Bible Translators", "wycliffe", "WT"},
+		{"Society of Biblical Literature", "sbl", "SL"},
+		{"Biblica", "biblica", "B"},
+		{"the bible league", "bible-league", "TL"},
+		{"Société biblique de Genève", "sbg", "SG"},
+		{"中国基督教两会", "china-christian-council", "CC"},
+		{"", "", "?"},
+	} {
+		require.Equal(t, tc.want, copyright_service.Initials(tc.name, tc.slug), tc.name)
+	}
+}
+
+// TestMarkLogoPlaceholders verifies that organizations without a logo URL, or whose logo
+// is missing or broken, are marked as the PDF draws them, and that PDFs are still rendered
+// for them.
+func TestMarkLogoPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	const (
+		goodURL   = "https://cdn.example.com/good.png"
+		brokenURL = "https://cdn.example.com/broken.png"
+		goneURL   = "https://cdn.example.com/gone.png"
+	)
+
+	var logo bytes.Buffer
+	require.NoError(t, png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 40, 20))))
+
+	fake := logo_service.NewFake(map[string]logo_service.Response{
+		goodURL: {Body: logo.Bytes(), ContentType: "image/png"},
+		// A truncated PNG passes sniffing but cannot be decoded.
+		brokenURL: {Body: logo.Bytes()[:40], ContentType: "image/png"},
+	})
+	dir := t.TempDir()
+
+	mgr := &copyright_service.Manager{
+		Config: config.Default().Copyright,
+		Logos:  logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, time.Hour, logo_service.Schemes{"https": fake}),
+	}
+
+	copyrights := []copyright_service.ByOrganizations{{
+		ProductCode: "N2ENG/NIV",
+		Copyright:   "© Biblica",
+		Organizations: []copyright_service.OrganizationsForCopyright{
+			{OrganizationID: 1, OrganizationName: "Biblica", OrganizationLogoURL: goodURL},
+			{OrganizationID: 2, OrganizationName: "Broken Logo", OrganizationLogoURL: brokenURL},
+			{OrganizationID: 3, OrganizationName: "Gone Logo", OrganizationLogoURL: goneURL},
+			{OrganizationID: 4, OrganizationName: "No Logo", OrganizationColor: "#f0c040"},
+		},
+	}}
+
+	placeholders := func(marked []copyright_service.ByOrganizations) []bool {
+		flags := make([]bool, 0, len(marked[0].Organizations))
+		for _, org := range marked[0].Organizations {
+			flags = append(flags, org.LogoPlaceholder)
+		}
+
+		return flags
+	}
+
+	// On a cold cache, the logo that can be fetched is not marked, and the broken one is.
+	marked := mgr.MarkLogoPlaceholders(t.Context(), copyrights)
+	require.Equal(t, []bool{false, true, true, true}, placeholders(marked))
+	require.False(t, copyrights[0].Organizations[1].LogoPlaceholder, "the input is left unchanged")
+
+	ctx, drawn := copyright_service.WithLogoReport(t.Context())
+
+	var out bytes.Buffer
+	require.NoError(t, mgr.ProducePdfCopyright(ctx, &out, copyrights,
+		copyright_service.Layout{}.ForMode(copyright_service.ModeAudio)))
+	require.True(t, bytes.HasPrefix(out.Bytes(), []byte("%PDF-")))
+	require.False(t, drawn.Complete(), "a PDF missing logos must not be cached")
+	require.Equal(t, []string{goodURL}, slices.Collect(maps.Keys(drawn.Digests())))
+
+	// The PDF drew what was marked, and the logos fetched for marking were reused.
+	require.Equal(t, []bool{false, true, true, true}, placeholders(mgr.MarkLogoPlaceholders(t.Context(), copyrights)))
+	require.Equal(t, 1, fake.Requests(goodURL))
+}
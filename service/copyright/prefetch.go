@@ -0,0 +1,177 @@
+package copyright
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	logo_service "biblebrain-services/service/logo"
+	metrics_service "biblebrain-services/service/metrics"
+	pdf_service "biblebrain-services/service/pdf"
+	sqlc "biblebrain-services/sqlc/generated"
+	util "biblebrain-services/util"
+)
+
+// PrefetchOptions narrows the logos warmed by PrefetchLogos.
+type PrefetchOptions struct {
+	// OrganizationIDs restricts the logos to those of the given organizations.
+	OrganizationIDs []uint32
+	// ProductCodes restricts the logos to those of the organizations of the given products.
+	ProductCodes []string
+	// Mode restricts ProductCodes to filesets of the given mode. Empty means every mode.
+	Mode string
+	// Revalidate checks every logo with its origin, even those the cache holds as fresh.
+	Revalidate bool
+}
+
+// PrefetchReport is the result of a PrefetchLogos run. Failures use the logo kinds of
+// AuditIssue.
+type PrefetchReport struct {
+	LogosScanned int          `json:"logosScanned"`
+	Downloaded   int          `json:"downloaded"`
+	Cached       int          `json:"cached"`
+	Failures     []AuditIssue `json:"failures"`
+}
+
+// PrefetchLogos downloads the distinct logos of organization_logos into the logo cache and
+// normalizes them as PDFs would, so that the first PDFs after a deploy or a logo update do
+// not wait for them. Logos that cannot be used are reported, not returned as an error.
+func (m *Manager) PrefetchLogos(ctx context.Context, opts PrefetchOptions) (PrefetchReport, error) {
+	report := PrefetchReport{Failures: []AuditIssue{}}
+
+	logoOrgs, err := m.prefetchURLs(ctx, opts)
+	if err != nil {
+		return report, err
+	}
+
+	urls := make([]string, 0, len(logoOrgs))
+	for logoURL := range logoOrgs {
+		urls = append(urls, logoURL)
+	}
+	sort.Strings(urls)
+
+	report.LogosScanned = len(urls)
+
+	if opts.Revalidate {
+		ctx = logo_service.WithRevalidation(ctx)
+	}
+
+	type result struct {
+		logo  logo_service.Logo
+		issue *AuditIssue
+	}
+
+	logos := m.logos()
+	box := m.logoBox(pdf_service.Configuration())
+	channel := make(chan result, len(urls))
+	sem := make(chan struct{}, m.Config.MaxConcurrentDownloads)
+
+	for _, logoURL := range urls {
+		sem <- struct{}{}
+		go func(logoURL string) {
+			defer func() { <-sem }()
+
+			logo, issue := auditLogo(ctx, logos, box, logoURL)
+			channel <- result{logo, issue}
+		}(logoURL)
+	}
+
+	for range urls {
+		res := <-channel
+
+		switch {
+		case res.issue != nil && res.issue.Kind == AuditLogoUnreachable:
+			report.Failures = append(report.Failures, issuesByOrganization(*res.issue, logoOrgs[res.issue.LogoURL])...)
+			metrics_service.Default().AddLogos(metrics_service.LogoFailed, 1)
+		case res.issue != nil:
+			report.Failures = append(report.Failures, issuesByOrganization(*res.issue, logoOrgs[res.issue.LogoURL])...)
+		case res.logo.Fetched:
+			report.Downloaded++
+			metrics_service.Default().AddLogos(metrics_service.LogoDownloaded, 1)
+		default:
+			report.Cached++
+			metrics_service.Default().AddLogos(metrics_service.LogoCached, 1)
+		}
+	}
+
+	sortLogoIssues(report.Failures)
+
+	return report, nil
+}
+
+// prefetchURLs returns the logo URLs in scope of opts, each with the organizations using it.
+func (m *Manager) prefetchURLs(ctx context.Context, opts PrefetchOptions) (map[string][]uint32, error) {
+	orgIDs := opts.OrganizationIDs
+
+	if len(opts.ProductCodes) > 0 {
+		productOrgs, err := m.productOrganizations(ctx, opts.ProductCodes, opts.Mode)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(orgIDs) > 0 {
+			productOrgs = slices.DeleteFunc(productOrgs, func(id uint32) bool { return !slices.Contains(orgIDs, id) })
+		}
+
+		if len(productOrgs) == 0 {
+			return map[string][]uint32{}, nil
+		}
+
+		orgIDs = productOrgs
+	}
+
+	var (
+		rows []sqlc.ListOrganizationLogosRow
+		err  error
+	)
+
+	if len(orgIDs) == 0 {
+		rows, err = m.Query.ListOrganizationLogos(ctx)
+	} else {
+		var filtered []sqlc.GetOrganizationLogosRow
+
+		filtered, err = m.Query.GetOrganizationLogos(ctx, orgIDs)
+		for _, row := range filtered {
+			rows = append(rows, sqlc.ListOrganizationLogosRow(row))
+		}
+	}
+
+	if err != nil {
+		util.LoggerFrom(ctx).Error("listing organization logos", "error", err)
+
+		return nil, fmt.Errorf("listing organization logos: %w", err)
+	}
+
+	logoOrgs := make(map[string][]uint32, len(rows))
+	for _, row := range rows {
+		if !slices.Contains(logoOrgs[row.Url.String], row.OrganizationID) {
+			logoOrgs[row.Url.String] = append(logoOrgs[row.Url.String], row.OrganizationID)
+		}
+	}
+
+	return logoOrgs, nil
+}
+
+// productOrganizations returns the IDs of the organizations of the copyrights of products.
+func (m *Manager) productOrganizations(ctx context.Context, products []string, mode string) ([]uint32, error) {
+	rows, err := m.auditCopyrightRows(ctx, AuditOptions{ProductCodes: products, Mode: mode})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint32, 0)
+
+	for _, row := range rows {
+		for part := range strings.SplitSeq(row.OrganizationIDList, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err == nil && !slices.Contains(ids, uint32(id)) {
+				ids = append(ids, uint32(id))
+			}
+		}
+	}
+
+	return ids, nil
+}
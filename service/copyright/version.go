@@ -0,0 +1,171 @@
+package copyright
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	metrics_service "biblebrain-services/service/metrics"
+	sqlc "biblebrain-services/sqlc/generated"
+	util "biblebrain-services/util"
+)
+
+// Version identifies the database state behind a package. It changes whenever a copyright, an
+// organization link, an organization, its translation or its logo involved in the package is
+// added, removed or updated.
+type Version struct {
+	Rows      int64
+	UpdatedAt time.Time
+}
+
+// IsEmpty reports whether no copyright matched the package.
+func (v Version) IsEmpty() bool {
+	return v.Rows == 0
+}
+
+// GetVersion returns the Version of the package of productCodes in mode, with organization
+// names in the configured language. It is a single aggregate query, much cheaper than
+// GetCopyrightBy.
+func (m *Manager) GetVersion(ctx context.Context, productCodes []string, mode string) (Version, error) {
+	start := time.Now()
+	row, err := m.Query.GetCopyrightsVersion(ctx, sqlc.GetCopyrightsVersionParams{
+		LanguageId:   m.Config.LanguageID,
+		ProductCodes: productCodes,
+		TypeCodes:    getTypeCodes(mode),
+	})
+	metrics_service.Default().ObserveQuery("GetCopyrightsVersion", time.Since(start))
+	if err != nil {
+		util.LoggerFrom(ctx).Error("fetching copyrights version", "error", err)
+
+		return Version{}, fmt.Errorf("GetCopyrightsVersion: %w", err)
+	}
+
+	return Version{Rows: row.RowCount, UpdatedAt: row.UpdatedAt.Time.UTC()}, nil
+}
+
+// LogoDigests maps the logo URLs of a package to the SHA-256 of their content. Logos that
+// are not cached, or could not be drawn, are left out.
+type LogoDigests map[string]string
+
+// String returns the sorted URLs and digests, one pair per line.
+func (d LogoDigests) String() string {
+	pairs := make([]string, 0, len(d))
+	for url, digest := range d {
+		pairs = append(pairs, url+" "+digest)
+	}
+
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, "\n")
+}
+
+// CachedLogoDigests returns the LogoDigests of the logos of copyrights in the logo cache,
+// without fetching any.
+func (m *Manager) CachedLogoDigests(ctx context.Context, copyrights []ByOrganizations) LogoDigests {
+	logos := m.logos()
+	digests := make(LogoDigests)
+
+	for _, copyright := range copyrights {
+		for _, org := range copyright.Organizations {
+			url := org.OrganizationLogoURL
+			if _, ok := digests[url]; ok || url == "" {
+				continue
+			}
+
+			if entry, ok := logos.Lookup(ctx, url); ok {
+				digests[url] = entry.Digest
+			}
+		}
+	}
+
+	return digests
+}
+
+// CacheKey returns the hex SHA-256 of everything that determines a rendered package: the
+// product codes (trimmed, deduplicated and sorted, since the output is ordered by product),
+// mode, format, language and layout, the Version of the data, the logos, so that a logo
+// replaced under the same URL is picked up, and the build, so that a release that changes
+// rendering does not serve documents rendered by the previous one.
+func CacheKey(
+	productCodes []string,
+	mode, format string,
+	languageID uint32,
+	layout Layout,
+	version Version,
+	logos LogoDigests,
+) string {
+	products := make([]string, 0, len(productCodes))
+	for _, code := range productCodes {
+		if code = strings.TrimSpace(code); code != "" {
+			products = append(products, code)
+		}
+	}
+
+	slices.Sort(products)
+	products = slices.Compact(products)
+
+	layout = layout.ForMode(mode)
+	buildVersion, commit := util.BuildInfo()
+
+	hash := sha256.New()
+	for _, part := range []string{
+		strings.Join(products, ","),
+		mode,
+		format,
+		strconv.FormatUint(uint64(languageID), 10),
+		layout.PageSize,
+		strconv.Itoa(layout.GridSize),
+		strconv.FormatInt(version.Rows, 10),
+		version.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		logos.String(),
+		buildVersion,
+		commit,
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ETag returns a weak entity tag for copyrights rendered in format, mode and layout with
+// logos. It hashes the copyrights normalized by product code and organization ID, so it does
+// not depend on the order rows come back in, together with the logos and the build. It is
+// weak since PDFs rendered from the same copyrights differ in their creation date.
+func ETag(copyrights []ByOrganizations, logos LogoDigests, format, mode string, layout Layout) string {
+	normalized := make([]ByOrganizations, len(copyrights))
+	for i, c := range copyrights {
+		c.Organizations = slices.Clone(c.Organizations)
+		slices.SortStableFunc(c.Organizations, func(a, b OrganizationsForCopyright) int {
+			return cmp.Compare(a.OrganizationID, b.OrganizationID)
+		})
+		normalized[i] = c
+	}
+
+	slices.SortStableFunc(normalized, func(a, b ByOrganizations) int {
+		return strings.Compare(a.ProductCode, b.ProductCode)
+	})
+
+	layout = layout.ForMode(mode)
+	buildVersion, commit := util.BuildInfo()
+
+	hash := sha256.New()
+	for _, part := range []string{
+		format, mode, layout.PageSize, strconv.Itoa(layout.GridSize), logos.String(), buildVersion, commit,
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	// Encoding into a hash cannot fail for these plain structs.
+	_ = json.NewEncoder(hash).Encode(normalized)
+
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+}
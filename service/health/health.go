@@ -0,0 +1,216 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	connection_service "biblebrain-services/service/connection"
+	secret_service "biblebrain-services/service/secret"
+	util "biblebrain-services/util"
+)
+
+// Check statuses.
+const (
+	StatusOK      = "ok"
+	StatusFail    = "fail"
+	StatusSkipped = "skipped"
+)
+
+// Reasons reported for checks that did not pass.
+const (
+	ReasonNotConfigured = "not configured"
+	ReasonTimeout       = "timed out"
+	ReasonFailed        = "check failed"
+)
+
+// ErrSkipped is returned by a check that is not configured; it does not fail the report.
+var ErrSkipped = errors.New("check skipped")
+
+// ErrUnhealthyStatus is returned by HTTPCheck for 5xx responses.
+var ErrUnhealthyStatus = errors.New("unhealthy HTTP status")
+
+// Check is one named dependency check.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	// Error is one of the generic reasons, never the error of the check.
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the outcome of all checks together with the build information.
+type Report struct {
+	Status  string   `json:"status"`
+	Version string   `json:"version"`
+	Commit  string   `json:"commit"`
+	Checks  []Result `json:"checks,omitempty"`
+}
+
+// Healthy reports whether no check failed.
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs a fixed set of checks concurrently, each bounded by a timeout.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+// NewChecker returns a Checker for checks, each of which is cancelled after timeout.
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// Live returns a report without running any check: the process is up.
+func Live() Report {
+	version, commit := util.BuildInfo()
+
+	return Report{Status: StatusOK, Version: version, Commit: commit}
+}
+
+// Run executes every check and returns their results in registration order.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Live()
+	report.Checks = make([]Result, len(c.checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range c.checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			report.Checks[i] = c.run(ctx, check)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusFail {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := Result{
+		Name:      check.Name,
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	// Reports are public, so they only carry a generic reason; the error, which may name
+	// hosts, secrets or paths, is logged.
+	switch {
+	case errors.Is(err, ErrSkipped):
+		result.Status = StatusSkipped
+		result.Error = ReasonNotConfigured
+	case errors.Is(err, context.DeadlineExceeded):
+		util.LoggerFrom(ctx).Warn("health check timed out", "check", check.Name, "error", err)
+		result.Status = StatusFail
+		result.Error = ReasonTimeout
+	case err != nil:
+		util.LoggerFrom(ctx).Warn("health check failed", "check", check.Name, "error", err)
+		result.Status = StatusFail
+		result.Error = ReasonFailed
+	}
+
+	return result
+}
+
+// DatabaseCheck verifies that the shared pool can be opened and answers a ping.
+func DatabaseCheck(conns *connection_service.Manager) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) error {
+		conn, err := conns.DB(ctx)
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+
+		if err := conn.PingContext(ctx); err != nil {
+			return fmt.Errorf("%w: %w", connection_service.ErrDBUnreachable, err)
+		}
+
+		return nil
+	}}
+}
+
+// SecretCheck verifies that the secret name can be resolved through provider.
+func SecretCheck(provider secret_service.Provider, name string) Check {
+	return Check{Name: "secret", Run: func(ctx context.Context) error {
+		if _, err := provider.GetSecret(ctx, name); err != nil {
+			return fmt.Errorf("resolving secret %q: %w", name, err)
+		}
+
+		return nil
+	}}
+}
+
+// WritableDirCheck verifies that a file can be created in dir, creating dir if needed.
+func WritableDirCheck(name, dir string) Check {
+	return Check{Name: name, Run: func(context.Context) error {
+		if err := os.MkdirAll(dir, 0o775); err != nil {
+			return fmt.Errorf("creating %q: %w", dir, err)
+		}
+
+		file, err := os.CreateTemp(dir, ".health-*")
+		if err != nil {
+			return fmt.Errorf("writing to %q: %w", dir, err)
+		}
+
+		file.Close()
+
+		if err := os.Remove(file.Name()); err != nil {
+			return fmt.Errorf("removing %q: %w", file.Name(), err)
+		}
+
+		return nil
+	}}
+}
+
+// HTTPCheck verifies that url answers a HEAD request without a server error. An empty url
+// skips the check.
+func HTTPCheck(name, url string) Check {
+	return Check{Name: name, Run: func(ctx context.Context) error {
+		if url == "" {
+			return fmt.Errorf("%w: no URL configured", ErrSkipped)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return fmt.Errorf("creating request for %q: %w", url, err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("requesting %q: %w", url, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: %d", ErrUnhealthyStatus, resp.StatusCode)
+		}
+
+		return nil
+	}}
+}
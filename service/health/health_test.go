@@ -0,0 +1,53 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	health_service "biblebrain-services/service/health"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errDown = errors.New("down")
+
+// TestCheckerRun verifies per-check statuses, skipped checks and the overall status.
+func TestCheckerRun(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := health_service.NewChecker(time.Second,
+		health_service.WritableDirCheck("logo_dir", filepath.Join(t.TempDir(), "copyright")),
+		health_service.HTTPCheck("logo_host", server.URL),
+		health_service.HTTPCheck("unconfigured", ""),
+	)
+
+	report := checker.Run(t.Context())
+	require.True(t, report.Healthy())
+	require.NotEmpty(t, report.Version)
+	require.Len(t, report.Checks, 3)
+	require.Equal(t, "logo_dir", report.Checks[0].Name)
+	require.Equal(t, health_service.StatusOK, report.Checks[0].Status)
+	require.Equal(t, health_service.StatusOK, report.Checks[1].Status)
+	require.Equal(t, health_service.StatusSkipped, report.Checks[2].Status)
+	require.Equal(t, health_service.ReasonNotConfigured, report.Checks[2].Error)
+
+	checker = health_service.NewChecker(time.Second, health_service.Check{
+		Name: "database",
+		Run:  func(context.Context) error { return errDown },
+	})
+
+	report = checker.Run(t.Context())
+	require.False(t, report.Healthy())
+	require.Equal(t, health_service.StatusFail, report.Checks[0].Status)
+	require.Equal(t, health_service.ReasonFailed, report.Checks[0].Error)
+}
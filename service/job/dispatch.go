@@ -0,0 +1,126 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	util "biblebrain-services/util"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// Dispatcher starts a created job without waiting for it.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, id string) error
+}
+
+// RunFunc runs one job, as Worker.Run does.
+type RunFunc func(ctx context.Context, id string) error
+
+// LocalDispatcher runs jobs in goroutines of the current process, a bounded number at once.
+// It suits the standalone server; a Lambda container may be frozen as soon as the response is
+// sent.
+type LocalDispatcher struct {
+	run      RunFunc
+	slots    chan struct{}
+	stopping chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// Verify at compile-time that *LocalDispatcher implements Dispatcher.
+var _ Dispatcher = (*LocalDispatcher)(nil)
+
+// NewLocalDispatcher returns a LocalDispatcher that runs jobs with run.
+func NewLocalDispatcher(run RunFunc, concurrency int) *LocalDispatcher {
+	return &LocalDispatcher{run: run, slots: make(chan struct{}, concurrency), stopping: make(chan struct{})}
+}
+
+// Dispatch queues job id. The job keeps the values of ctx, such as the logger, but not its
+// cancellation, since it outlives the request.
+func (d *LocalDispatcher) Dispatch(ctx context.Context, id string) error {
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	d.wg.Add(1)
+
+	go func() {
+		defer d.wg.Done()
+		defer cancel()
+
+		go func() {
+			select {
+			case <-d.stopping:
+				cancel()
+			case <-jobCtx.Done():
+			}
+		}()
+
+		select {
+		case d.slots <- struct{}{}:
+			defer func() { <-d.slots }()
+		case <-jobCtx.Done():
+			return
+		}
+
+		if err := d.run(jobCtx, id); err != nil {
+			util.LoggerFrom(jobCtx).Warn("Job run failed", "job_id", id, "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Close cancels the running and queued jobs and waits for them to return.
+func (d *LocalDispatcher) Close() {
+	d.stopOnce.Do(func() { close(d.stopping) })
+	d.wg.Wait()
+}
+
+// Event is the payload of the asynchronous invocation sent by LambdaDispatcher.
+type Event struct {
+	JobID string `json:"jobId"`
+}
+
+// LambdaDispatcher runs each job in its own asynchronous invocation of Function, which is
+// expected to pass the Event to Worker.Run.
+type LambdaDispatcher struct {
+	Client   *lambda.Client
+	Function string
+}
+
+// Verify at compile-time that *LambdaDispatcher implements Dispatcher.
+var _ Dispatcher = (*LambdaDispatcher)(nil)
+
+// NewLambdaDispatcher returns a LambdaDispatcher for function using the default AWS configuration.
+func NewLambdaDispatcher(ctx context.Context, function string) (*LambdaDispatcher, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithDefaultRegion("us-west-2"))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+
+	return &LambdaDispatcher{Client: lambda.NewFromConfig(cfg), Function: function}, nil
+}
+
+// Dispatch invokes Function asynchronously with the Event of job id.
+func (d *LambdaDispatcher) Dispatch(ctx context.Context, id string) error {
+	payload, err := json.Marshal(Event{JobID: id})
+	if err != nil {
+		return fmt.Errorf("encoding job event: %w", err)
+	}
+
+	_, err = d.Client.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(d.Function),
+		InvocationType: types.InvocationTypeEvent,
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("invoking %s for job %s: %w", d.Function, id, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBStore keeps job records in a DynamoDB table with the string partition key "id".
+// The record is stored as JSON in the "job" attribute; "expiresAt" holds the epoch second
+// after which DynamoDB TTL may delete it.
+type DynamoDBStore struct {
+	Client *dynamodb.Client
+	Table  string
+	TTL    time.Duration
+}
+
+// Verify at compile-time that *DynamoDBStore implements Store.
+var _ Store = (*DynamoDBStore)(nil)
+
+// NewDynamoDBStore returns a DynamoDBStore for table using the default AWS configuration.
+func NewDynamoDBStore(ctx context.Context, table string, ttl time.Duration) (*DynamoDBStore, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithDefaultRegion("us-west-2"))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+
+	return &DynamoDBStore{Client: dynamodb.NewFromConfig(cfg), Table: table, TTL: ttl}, nil
+}
+
+// Create writes a new job record; it fails with ErrExists when the ID is taken.
+func (s *DynamoDBStore) Create(ctx context.Context, job *Job) error {
+	err := s.put(ctx, job, "attribute_not_exists(id)")
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return fmt.Errorf("%w: %s", ErrExists, job.ID)
+	}
+
+	return err
+}
+
+// Get reads the record of job id.
+func (s *DynamoDBStore) Get(ctx context.Context, id string) (*Job, error) {
+	output, err := s.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.Table),
+		Key:            map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading job %s: %w", id, err)
+	}
+
+	attr, ok := output.Item["job"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	var job Job
+	if err := json.Unmarshal([]byte(attr.Value), &job); err != nil {
+		return nil, fmt.Errorf("decoding job %s: %w", id, err)
+	}
+
+	return &job, nil
+}
+
+// Update replaces the record of an existing job.
+func (s *DynamoDBStore) Update(ctx context.Context, job *Job) error {
+	err := s.put(ctx, job, "attribute_exists(id)")
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return fmt.Errorf("%w: %s", ErrNotFound, job.ID)
+	}
+
+	return err
+}
+
+func (s *DynamoDBStore) put(ctx context.Context, job *Job, condition string) error {
+	content, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encoding job %s: %w", job.ID, err)
+	}
+
+	item := map[string]types.AttributeValue{
+		"id":  &types.AttributeValueMemberS{Value: job.ID},
+		"job": &types.AttributeValueMemberS{Value: string(content)},
+	}
+
+	if s.TTL > 0 {
+		expiresAt := job.CreatedAt.Add(s.TTL).Unix()
+		item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)}
+	}
+
+	_, err = s.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.Table),
+		Item:                item,
+		ConditionExpression: aws.String(condition),
+	})
+	if err != nil {
+		return fmt.Errorf("writing job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	dirPerm  = 0o750
+	filePerm = 0o640
+)
+
+// FSStore keeps each job as a JSON file named after its ID in Dir/records.
+type FSStore struct {
+	Dir string
+}
+
+// Verify at compile-time that *FSStore implements Store.
+var _ Store = (*FSStore)(nil)
+
+// Create writes a new job record; it fails with ErrExists when the ID is taken.
+func (s *FSStore) Create(_ context.Context, job *Job) error {
+	if !ValidID(job.ID) {
+		return fmt.Errorf("%w: %q", ErrInvalidID, job.ID)
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.Dir, "records"), dirPerm); err != nil {
+		return fmt.Errorf("creating job directory: %w", err)
+	}
+
+	content, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encoding job %s: %w", job.ID, err)
+	}
+
+	file, err := os.OpenFile(s.path(job.ID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, filePerm)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrExists, job.ID)
+	} else if err != nil {
+		return fmt.Errorf("writing job %s: %w", job.ID, err)
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+
+		return fmt.Errorf("writing job %s: %w", job.ID, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// Get reads the record of job id.
+func (s *FSStore) Get(_ context.Context, id string) (*Job, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	content, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	} else if err != nil {
+		return nil, fmt.Errorf("reading job %s: %w", id, err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(content, &job); err != nil {
+		return nil, fmt.Errorf("decoding job %s: %w", id, err)
+	}
+
+	return &job, nil
+}
+
+// Update replaces the record of an existing job. The file is replaced atomically, so a
+// concurrent Get never sees a partial record.
+func (s *FSStore) Update(_ context.Context, job *Job) error {
+	if !ValidID(job.ID) {
+		return fmt.Errorf("%w: %s", ErrNotFound, job.ID)
+	}
+
+	if _, err := os.Stat(s.path(job.ID)); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, job.ID)
+	}
+
+	content, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encoding job %s: %w", job.ID, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(s.Dir, "records"), job.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing job %s: %w", job.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("writing job %s: %w", job.ID, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing job %s: %w", job.ID, err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(job.ID)); err != nil {
+		return fmt.Errorf("writing job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+func (s *FSStore) path(id string) string {
+	return filepath.Join(s.Dir, "records", id+".json")
+}
+
+// FSResultStore keeps each result as a file named after its job ID in Dir/results.
+type FSResultStore struct {
+	Dir string
+}
+
+// Verify at compile-time that *FSResultStore implements ResultStore.
+var _ ResultStore = (*FSResultStore)(nil)
+
+// Put copies body to the result file of job id.
+func (s *FSResultStore) Put(_ context.Context, id string, body io.Reader, _ string) error {
+	if !ValidID(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.Dir, "results"), dirPerm); err != nil {
+		return fmt.Errorf("creating result directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path(id), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePerm)
+	if err != nil {
+		return fmt.Errorf("writing result %s: %w", id, err)
+	}
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+
+		return fmt.Errorf("writing result %s: %w", id, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing result %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// Open returns the result of job id.
+func (s *FSResultStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("%w: result %s", ErrNotFound, id)
+	}
+
+	file, err := os.Open(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: result %s", ErrNotFound, id)
+	} else if err != nil {
+		return nil, fmt.Errorf("opening result %s: %w", id, err)
+	}
+
+	return file, nil
+}
+
+func (s *FSResultStore) path(id string) string {
+	return filepath.Join(s.Dir, "results", id)
+}
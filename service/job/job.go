@@ -0,0 +1,124 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"biblebrain-services/config"
+	copyright_service "biblebrain-services/service/copyright"
+)
+
+// Status is the state of a job.
+type Status string
+
+// Job states. A job moves from queued to running to either succeeded or failed.
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Done reports whether s is final.
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed
+}
+
+// Request describes the copyright package to produce, as accepted by POST api/copyright.
+type Request struct {
+	Products   []string                 `json:"products"`
+	Format     string                   `json:"format"`
+	Mode       string                   `json:"mode"`
+	LanguageID uint32                   `json:"languageId"`
+	Layout     copyright_service.Layout `json:"layout"`
+}
+
+// Job is the record of an asynchronous copyright request.
+type Job struct {
+	ID      string  `json:"id"`
+	Status  Status  `json:"status"`
+	Request Request `json:"request"`
+	// Error is a client-facing reason for a failed job.
+	Error string `json:"error,omitempty"`
+	// ContentType and Size describe the result of a succeeded job.
+	ContentType string    `json:"contentType,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Store keeps job records.
+type Store interface {
+	Create(ctx context.Context, job *Job) error
+	Get(ctx context.Context, id string) (*Job, error)
+	Update(ctx context.Context, job *Job) error
+}
+
+// ResultStore keeps the documents produced by jobs.
+type ResultStore interface {
+	// Put stores body as the result of job id. Implementations may need body to be seekable,
+	// so callers pass a file.
+	Put(ctx context.Context, id string, body io.Reader, contentType string) error
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+}
+
+// Presigner is implemented by result stores whose results can be downloaded directly, which
+// spares the API from proxying large documents.
+type Presigner interface {
+	PresignGet(ctx context.Context, id string, ttl time.Duration) (string, error)
+}
+
+// ErrNotFound indicates that the job or its result does not exist.
+var ErrNotFound = errors.New("job not found")
+
+// ErrExists indicates that a job with the same ID was already created.
+var ErrExists = errors.New("job already exists")
+
+// ErrInvalidID indicates an ID that was not returned by NewID.
+var ErrInvalidID = errors.New("invalid job ID")
+
+// ErrUnknownStore indicates that the configuration names an unsupported store.
+var ErrUnknownStore = errors.New("unknown job store")
+
+// idPattern matches the IDs returned by NewID, so that IDs taken from URLs are safe to use as
+// file names and object keys.
+var idPattern = regexp.MustCompile(`^[A-Z2-7]{26}$`)
+
+// NewID returns a random job ID.
+func NewID() string {
+	return rand.Text()
+}
+
+// ValidID reports whether id could have been returned by NewID.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// NewStore builds the job store for cfg.Store.
+func NewStore(ctx context.Context, cfg config.Jobs) (Store, error) {
+	switch cfg.Store {
+	case config.JobStoreFS:
+		return &FSStore{Dir: cfg.Dir}, nil
+	case config.JobStoreDynamoDB:
+		return NewDynamoDBStore(ctx, cfg.Table, cfg.TTL)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStore, cfg.Store)
+	}
+}
+
+// NewResultStore builds the result store for cfg.ResultStore.
+func NewResultStore(ctx context.Context, cfg config.Jobs) (ResultStore, error) {
+	switch cfg.ResultStore {
+	case config.ResultStoreFS:
+		return &FSResultStore{Dir: cfg.Dir}, nil
+	case config.ResultStoreS3:
+		return NewS3ResultStore(ctx, cfg.Bucket, cfg.Prefix)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStore, cfg.ResultStore)
+	}
+}
@@ -0,0 +1,90 @@
+package job_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	job_service "biblebrain-services/service/job"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFSStores verifies the job record lifecycle and result round trip of the fs stores.
+func TestFSStores(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := &job_service.FSStore{Dir: dir}
+	results := &job_service.FSResultStore{Dir: dir}
+
+	job := &job_service.Job{
+		ID:        job_service.NewID(),
+		Status:    job_service.StatusQueued,
+		Request:   job_service.Request{Products: []string{"N2ENG/NIV"}, Format: "pdf", Mode: "audio"},
+		CreatedAt: time.Now().UTC(),
+	}
+	require.True(t, job_service.ValidID(job.ID))
+	require.NoError(t, store.Create(t.Context(), job))
+	require.ErrorIs(t, store.Create(t.Context(), job), job_service.ErrExists)
+
+	job.Status = job_service.StatusSucceeded
+	require.NoError(t, store.Update(t.Context(), job))
+
+	got, err := store.Get(t.Context(), job.ID)
+	require.NoError(t, err)
+	require.Equal(t, job_service.StatusSucceeded, got.Status)
+	require.Equal(t, []string{"N2ENG/NIV"}, got.Request.Products)
+
+	_, err = store.Get(t.Context(), job_service.NewID())
+	require.ErrorIs(t, err, job_service.ErrNotFound)
+	_, err = store.Get(t.Context(), "../../etc/passwd")
+	require.ErrorIs(t, err, job_service.ErrNotFound)
+	require.ErrorIs(t, store.Update(t.Context(), &job_service.Job{ID: job_service.NewID()}), job_service.ErrNotFound)
+
+	require.NoError(t, results.Put(t.Context(), job.ID, strings.NewReader("%PDF"), job_service.ContentTypePDF))
+
+	result, err := results.Open(t.Context(), job.ID)
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(result)
+	require.NoError(t, err)
+	require.NoError(t, result.Close())
+	require.Equal(t, "%PDF", string(content))
+
+	_, err = results.Open(t.Context(), job_service.NewID())
+	require.ErrorIs(t, err, job_service.ErrNotFound)
+}
+
+// TestLocalDispatcher verifies that dispatched jobs run after the request context ends and
+// that Close cancels the jobs still running.
+func TestLocalDispatcher(t *testing.T) {
+	t.Parallel()
+
+	var ran atomic.Int32
+
+	started := make(chan struct{})
+	dispatcher := job_service.NewLocalDispatcher(func(ctx context.Context, id string) error {
+		ran.Add(1)
+
+		if id == "block" {
+			close(started)
+			<-ctx.Done()
+
+			return ctx.Err()
+		}
+
+		return nil
+	}, 1)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	require.NoError(t, dispatcher.Dispatch(ctx, "block"))
+	cancel()
+
+	<-started
+	dispatcher.Close()
+	require.Equal(t, int32(1), ran.Load())
+}
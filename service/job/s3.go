@@ -0,0 +1,88 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3ResultStore keeps each result as the object Prefix+ID in Bucket.
+type S3ResultStore struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+// Verify at compile-time that *S3ResultStore implements ResultStore and Presigner.
+var (
+	_ ResultStore = (*S3ResultStore)(nil)
+	_ Presigner   = (*S3ResultStore)(nil)
+)
+
+// NewS3ResultStore returns an S3ResultStore for bucket using the default AWS configuration.
+func NewS3ResultStore(ctx context.Context, bucket, prefix string) (*S3ResultStore, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithDefaultRegion("us-west-2"))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+
+	return &S3ResultStore{Client: s3.NewFromConfig(cfg), Bucket: bucket, Prefix: prefix}, nil
+}
+
+// Put uploads body as the result of job id. body should be seekable, such as a file, so that
+// the upload can be signed without buffering it in memory.
+func (s *S3ResultStore) Put(ctx context.Context, id string, body io.Reader, contentType string) error {
+	_, err := s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(s.key(id)),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading result %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// Open downloads the result of job id.
+func (s *S3ResultStore) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(id)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: result %s", ErrNotFound, id)
+		}
+
+		return nil, fmt.Errorf("downloading result %s: %w", id, err)
+	}
+
+	return output.Body, nil
+}
+
+// PresignGet returns a URL from which the result of job id can be downloaded for ttl.
+func (s *S3ResultStore) PresignGet(ctx context.Context, id string, ttl time.Duration) (string, error) {
+	request, err := s3.NewPresignClient(s.Client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(id)),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("presigning result %s: %w", id, err)
+	}
+
+	return request.URL, nil
+}
+
+func (s *S3ResultStore) key(id string) string {
+	return s.Prefix + id
+}
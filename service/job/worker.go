@@ -0,0 +1,168 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"biblebrain-services/config"
+	connection_service "biblebrain-services/service/connection"
+	copyright_service "biblebrain-services/service/copyright"
+	tracing_service "biblebrain-services/service/tracing"
+	util "biblebrain-services/util"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Content types of job results.
+const (
+	ContentTypePDF  = "application/pdf"
+	ContentTypeJSON = "application/json"
+)
+
+// Worker runs jobs: it renders the requested package into a temporary file and stores it in
+// the result store.
+type Worker struct {
+	Jobs        Store
+	Results     ResultStore
+	Connections *connection_service.Manager
+	Config      config.Copyright
+	// Timeout bounds a single run.
+	Timeout time.Duration
+}
+
+// NewWorker returns a Worker for the stores, the database pool and the configuration.
+func NewWorker(jobs Store, results ResultStore, conns *connection_service.Manager, cfg *config.Config) *Worker {
+	return &Worker{Jobs: jobs, Results: results, Connections: conns, Config: cfg.Copyright, Timeout: cfg.Jobs.Timeout}
+}
+
+// Run runs job id and records its outcome. A job that is already done is left alone, so a
+// redelivered job is not produced twice. The returned error is for logging only; clients
+// see the job's client-facing Error.
+func (w *Worker) Run(ctx context.Context, id string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	ctx, span := tracing_service.Tracer().Start(ctx, "RunJob", trace.WithAttributes(attribute.String("job.id", id)))
+	defer func() { tracing_service.End(span, err) }()
+
+	logger := util.LoggerFrom(ctx).With("job_id", id)
+	ctx = util.WithLogger(ctx, logger)
+
+	job, err := w.Jobs.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if job.Status.Done() {
+		logger.Info("Job already done", "status", job.Status)
+
+		return nil
+	}
+
+	job.Status = StatusRunning
+	job.UpdatedAt = time.Now().UTC()
+
+	if err := w.Jobs.Update(ctx, job); err != nil {
+		return err
+	}
+
+	logger.Info("Running job", "products", job.Request.Products, "mode", job.Request.Mode)
+
+	contentType, size, err := w.produce(ctx, job)
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = "The copyright package could not be generated"
+
+		if errors.Is(err, copyright_service.ErrProductsNotFound) {
+			job.Error = "No copyrights found for the provided products"
+		}
+
+		logger.Error("Job failed", "error", err)
+	} else {
+		job.Status = StatusSucceeded
+		job.ContentType = contentType
+		job.Size = size
+
+		logger.Info("Job succeeded", "size", size)
+	}
+
+	job.UpdatedAt = time.Now().UTC()
+
+	// The run may have used up the timeout; the outcome must still be recorded.
+	if updateErr := w.Jobs.Update(context.WithoutCancel(ctx), job); updateErr != nil {
+		return errors.Join(err, updateErr)
+	}
+
+	return err
+}
+
+// produce renders the package of job and stores it, returning its content type and size.
+func (w *Worker) produce(ctx context.Context, job *Job) (string, int64, error) {
+	sqlCon, err := w.Connections.DB(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	cfg := w.Config
+	if job.Request.LanguageID != 0 {
+		cfg.LanguageID = job.Request.LanguageID
+	}
+
+	cser := copyright_service.New(sqlCon, cfg)
+
+	copyrights, err := cser.GetCopyrightBy(ctx, job.Request.Products, job.Request.Mode)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if len(copyrights) == 0 {
+		return "", 0, copyright_service.ErrProductsNotFound
+	}
+
+	// Results go through a file so that large packages are not held in memory.
+	file, err := os.CreateTemp("", "copyright-job-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("creating result file: %w", err)
+	}
+
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	var contentType string
+
+	switch job.Request.Format {
+	case "pdf":
+		contentType = ContentTypePDF
+		err = cser.ProducePdfCopyright(ctx, file, copyrights, job.Request.Layout.ForMode(job.Request.Mode))
+	default:
+		contentType = ContentTypeJSON
+		err = json.NewEncoder(file).Encode(cser.MarkLogoPlaceholders(ctx, copyrights))
+	}
+
+	if err != nil {
+		return "", 0, fmt.Errorf("rendering result: %w", err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, fmt.Errorf("rewinding result file: %w", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, fmt.Errorf("rewinding result file: %w", err)
+	}
+
+	if err := w.Results.Put(ctx, job.ID, file, contentType); err != nil {
+		return "", 0, err
+	}
+
+	return contentType, size, nil
+}
@@ -0,0 +1,329 @@
+package logo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register the GIF decoder for image.DecodeConfig.
+	_ "image/jpeg" // Register the JPEG decoder for image.DecodeConfig.
+	_ "image/png"  // Register the PNG decoder for image.DecodeConfig.
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	util "biblebrain-services/util"
+
+	"github.com/srwiley/oksvg"
+	_ "golang.org/x/image/webp" // Register the WebP decoder for image.DecodeConfig.
+)
+
+// Cache fetches logos once and keeps them in a Store. A logo is used as is for TTL after
+// the origin last confirmed it, then revalidated with If-None-Match; when the origin fails,
+// other than answering that the logo is gone, the stale logo is used. Concurrent requests
+// for a URL share one download.
+type Cache struct {
+	Store Store
+	// Dir holds the local copies read by the PDF renderer.
+	Dir     string
+	TTL     time.Duration
+	Fetcher Fetcher
+
+	mu      sync.Mutex
+	calls   map[string]*call
+	entries map[string]Entry
+}
+
+// call is a load in progress, awaited by concurrent requests for the same URL.
+type call struct {
+	done chan struct{}
+	logo Logo
+	err  error
+}
+
+// NewCache returns a Cache over store with local copies in dir, downloading with fetcher.
+func NewCache(store Store, dir string, ttl time.Duration, fetcher Fetcher) *Cache {
+	return &Cache{
+		Store:   store,
+		Dir:     dir,
+		TTL:     ttl,
+		Fetcher: fetcher,
+		calls:   make(map[string]*call),
+		entries: make(map[string]Entry),
+	}
+}
+
+// Get returns the logo at url, downloading or revalidating it as needed.
+func (c *Cache) Get(ctx context.Context, url string) (Logo, error) {
+	key := Key(url)
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && c.fresh(ctx, entry) {
+		c.mu.Unlock()
+
+		if logo, ok := c.local(entry); ok {
+			return logo, nil
+		}
+
+		c.mu.Lock()
+	}
+
+	if pending, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+
+		select {
+		case <-pending.done:
+			logo := pending.logo
+			logo.Fetched = false
+
+			return logo, pending.err
+		case <-ctx.Done():
+			return Logo{}, fmt.Errorf("waiting for logo %s: %w", url, ctx.Err())
+		}
+	}
+
+	pending := &call{done: make(chan struct{})}
+	c.calls[key] = pending
+	c.mu.Unlock()
+
+	// The load is shared, so it must not be cut short by the request that happened to start it.
+	pending.logo, pending.err = c.load(context.WithoutCancel(ctx), key, url)
+
+	c.mu.Lock()
+	delete(c.calls, key)
+
+	if pending.err == nil {
+		c.entries[key] = pending.logo.Entry
+	}
+	c.mu.Unlock()
+	close(pending.done)
+
+	return pending.logo, pending.err
+}
+
+// Lookup returns the entry of url known to the cache, without fetching or revalidating it:
+// false means the logo was never fetched successfully, or its entry expired from the store.
+func (c *Cache) Lookup(ctx context.Context, url string) (Entry, bool) {
+	key := Key(url)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok {
+		return entry, true
+	}
+
+	entry, err := c.Store.Entry(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			util.LoggerFrom(ctx).Warn("Failed to read logo entry", "url", url, "error", err)
+		}
+
+		return Entry{}, false
+	}
+
+	return entry, true
+}
+
+// fresh reports whether entry may be used without revalidation.
+func (c *Cache) fresh(ctx context.Context, entry Entry) bool {
+	return time.Since(entry.CheckedAt) < c.TTL && !revalidating(ctx)
+}
+
+type revalidateKey struct{}
+
+// WithRevalidation returns a context whose logos are revalidated with their origin even
+// while fresh, such as to pick up logos replaced under the same URL.
+func WithRevalidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, revalidateKey{}, true)
+}
+
+func revalidating(ctx context.Context) bool {
+	revalidate, _ := ctx.Value(revalidateKey{}).(bool)
+
+	return revalidate
+}
+
+// local returns the logo of entry if its local copy exists.
+func (c *Cache) local(entry Entry) (Logo, bool) {
+	name := dataPath(c.Dir, entry.Digest)
+	if _, err := os.Stat(name); err != nil {
+		return Logo{}, false
+	}
+
+	return Logo{Entry: entry, Path: name}, true
+}
+
+func (c *Cache) load(ctx context.Context, key, url string) (Logo, error) {
+	logger := util.LoggerFrom(ctx)
+
+	entry, err := c.Store.Entry(ctx, key)
+	cached := err == nil
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		logger.Warn("Failed to read logo entry", "url", url, "error", err)
+	}
+
+	if cached && c.fresh(ctx, entry) {
+		logo, err := c.materialize(ctx, entry, nil)
+		if err == nil {
+			return logo, nil
+		}
+
+		// The content expired from the store before its entry, or cannot be read: download it
+		// again.
+		logUnreadable(ctx, url, err)
+
+		cached = false
+	}
+
+	var etag string
+	if cached {
+		etag = entry.ETag
+	}
+
+	response, err := c.Fetcher.Fetch(ctx, url, etag)
+	if err != nil {
+		// A logo that is gone is no longer used, but an unreachable origin does not lose it.
+		var statusErr *StatusError
+		if cached && !(errors.As(err, &statusErr) && statusErr.Gone()) {
+			logger.Warn("Failed to revalidate logo, using the cached one", "url", url, "error", err)
+
+			return c.materialize(ctx, entry, nil)
+		}
+
+		return Logo{}, err
+	}
+
+	if response.NotModified {
+		entry.CheckedAt = time.Now().UTC()
+		if err := c.Store.PutEntry(ctx, key, entry); err != nil {
+			logger.Warn("Failed to store logo entry", "url", url, "error", err)
+		}
+
+		logo, err := c.materialize(ctx, entry, nil)
+		if err == nil {
+			return logo, nil
+		}
+
+		logUnreadable(ctx, url, err)
+
+		if response, err = c.Fetcher.Fetch(ctx, url, ""); err != nil {
+			return Logo{}, err
+		}
+	}
+
+	entry = describe(url, response)
+
+	// Content goes first, so that a stored entry always refers to stored content.
+	if err := c.Store.PutData(ctx, entry.Digest, response.Body); err != nil {
+		logger.Warn("Failed to store logo", "url", url, "error", err)
+	} else if err := c.Store.PutEntry(ctx, key, entry); err != nil {
+		logger.Warn("Failed to store logo entry", "url", url, "error", err)
+	}
+
+	logo, err := c.materialize(ctx, entry, response.Body)
+	logo.Fetched = true
+
+	return logo, err
+}
+
+// logUnreadable logs a cached logo whose content cannot be read, unless it only expired.
+func logUnreadable(ctx context.Context, url string, err error) {
+	if !errors.Is(err, ErrNotFound) {
+		util.LoggerFrom(ctx).Warn("Failed to read cached logo, downloading it again", "url", url, "error", err)
+	}
+}
+
+// materialize makes sure the content of entry has a local copy, reading it from the store
+// unless data is given.
+func (c *Cache) materialize(ctx context.Context, entry Entry, data []byte) (Logo, error) {
+	if logo, ok := c.local(entry); ok {
+		return logo, nil
+	}
+
+	if data == nil {
+		var err error
+		if data, err = c.Store.Data(ctx, entry.Digest); err != nil {
+			return Logo{}, fmt.Errorf("reading logo %s: %w", entry.URL, err)
+		}
+	}
+
+	name := dataPath(c.Dir, entry.Digest)
+	if err := writeFile(name, data); err != nil {
+		return Logo{}, err
+	}
+
+	return Logo{Entry: entry, Path: name}, nil
+}
+
+// describe builds the entry of a downloaded logo.
+func describe(url string, resp Response) Entry {
+	now := time.Now().UTC()
+	sum := sha256.Sum256(resp.Body)
+	contentType, ext := format(url, resp.ContentType, resp.Body)
+
+	entry := Entry{
+		URL:         url,
+		ContentType: contentType,
+		Ext:         ext,
+		ETag:        resp.ETag,
+		Digest:      hex.EncodeToString(sum[:]),
+		Size:        int64(len(resp.Body)),
+		FetchedAt:   now,
+		CheckedAt:   now,
+	}
+
+	if ext == "svg" {
+		if icon, err := oksvg.ReadIconStream(bytes.NewReader(resp.Body)); err == nil {
+			entry.Width, entry.Height = int(icon.ViewBox.W), int(icon.ViewBox.H)
+		}
+	} else if cfg, _, err := image.DecodeConfig(bytes.NewReader(resp.Body)); err == nil {
+		entry.Width, entry.Height = cfg.Width, cfg.Height
+	}
+
+	return entry
+}
+
+// format returns the media type and renderer format of a logo, from the Content-Type
+// header, else the content, else the extension of the URL.
+func format(url, contentType string, body []byte) (string, string) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/octet-stream" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+
+	switch mediaType {
+	case "image/png":
+		return mediaType, "png"
+	case "image/jpeg":
+		return mediaType, "jpg"
+	case "image/gif":
+		return mediaType, "gif"
+	case "image/webp":
+		return mediaType, "webp"
+	case "image/svg+xml":
+		return mediaType, "svg"
+	}
+
+	// SVG is sniffed as XML or text; fall back to the extension.
+	name, _, _ := strings.Cut(url, "?")
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	if ext == "jpeg" {
+		ext = "jpg"
+	}
+
+	if ext == "svg" {
+		return "image/svg+xml", ext
+	}
+
+	return mediaType, ext
+}
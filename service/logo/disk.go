@@ -0,0 +1,131 @@
+package logo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	dirPerm  = 0o750
+	filePerm = 0o640
+)
+
+// Disk keeps entries as JSON files under Dir/entries and content under Dir/data, both
+// sharded by the first two characters of their hash. Content is laid out as the Cache lays
+// out its local copies, so with a Disk store in the Cache's Dir logos are never copied.
+type Disk struct {
+	Dir string
+}
+
+// Verify at compile-time that *Disk implements Store.
+var _ Store = (*Disk)(nil)
+
+// Entry reads the entry stored under key.
+func (d *Disk) Entry(_ context.Context, key string) (Entry, error) {
+	if err := validHash(key); err != nil {
+		return Entry{}, err
+	}
+
+	raw, err := os.ReadFile(d.entryPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Entry{}, ErrNotFound
+	} else if err != nil {
+		return Entry{}, fmt.Errorf("reading logo entry %s: %w", key, err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return Entry{}, fmt.Errorf("decoding logo entry %s: %w", key, err)
+	}
+
+	return entry, nil
+}
+
+// PutEntry writes entry under key.
+func (d *Disk) PutEntry(_ context.Context, key string, entry Entry) error {
+	if err := validHash(key); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding logo entry %s: %w", key, err)
+	}
+
+	return writeFile(d.entryPath(key), raw)
+}
+
+// Data reads the content whose SHA-256 is digest.
+func (d *Disk) Data(_ context.Context, digest string) ([]byte, error) {
+	if err := validHash(digest); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(dataPath(d.Dir, digest))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("reading logo %s: %w", digest, err)
+	}
+
+	return data, nil
+}
+
+// PutData writes data under digest.
+func (d *Disk) PutData(_ context.Context, digest string, data []byte) error {
+	if err := validHash(digest); err != nil {
+		return err
+	}
+
+	return writeFile(dataPath(d.Dir, digest), data)
+}
+
+func (d *Disk) entryPath(key string) string {
+	return filepath.Join(d.Dir, "entries", key[:2], key+".json")
+}
+
+func dataPath(dir, digest string) string {
+	return filepath.Join(dir, "data", digest[:2], digest)
+}
+
+// writeFile writes data under a temporary name and renames it to name, so a concurrent
+// reader, such as a PDF being rendered, never sees a partial file.
+func writeFile(name string, data []byte) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return fmt.Errorf("creating logo directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+
+	if err := tmp.Chmod(filePerm); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+
+	return nil
+}
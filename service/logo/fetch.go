@@ -0,0 +1,211 @@
+package logo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	tracing_service "biblebrain-services/service/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Response is the answer of an origin to a logo download.
+type Response struct {
+	// NotModified reports that the logo still has the requested entity tag; Body is empty.
+	NotModified bool
+	Body        []byte
+	ContentType string
+	ETag        string
+}
+
+// Fetcher downloads logos from their origin. When etag is not empty and the logo still has
+// that entity tag, Fetch may answer NotModified instead of sending the content.
+type Fetcher interface {
+	Fetch(ctx context.Context, url, etag string) (Response, error)
+}
+
+// StatusError is returned when the origin answers a logo download with an unexpected status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("logo download of %q returned %d", e.URL, e.StatusCode)
+}
+
+// Is makes StatusError match ErrStatus.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrStatus
+}
+
+// Gone reports whether the status says the logo no longer exists, rather than that the
+// origin is failing.
+func (e *StatusError) Gone() bool {
+	return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+}
+
+// ErrTooManyRedirects indicates a logo download that kept being redirected.
+var ErrTooManyRedirects = errors.New("too many logo redirects")
+
+// Schemes dispatches each download to the Fetcher of the URL scheme, such as "https" or "s3".
+// Other schemes are rejected with ErrSchemeNotAllowed.
+type Schemes map[string]Fetcher
+
+// Verify at compile-time that Schemes implements Fetcher.
+var _ Fetcher = Schemes(nil)
+
+// Fetch downloads rawURL with the Fetcher of its scheme.
+func (s Schemes) Fetch(ctx context.Context, rawURL, etag string) (Response, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Response{}, fmt.Errorf("parsing logo URL %q: %w", rawURL, err)
+	}
+
+	fetcher, ok := s[parsed.Scheme]
+	if !ok {
+		return Response{}, reject(rawURL, ErrSchemeNotAllowed, parsed.Scheme)
+	}
+
+	return fetcher.Fetch(ctx, rawURL, etag)
+}
+
+// HTTPFetcher downloads logos over HTTP, retrying transport errors, 429 and 5xx answers up to
+// Retries times, waiting Backoff before the first retry and twice as long before each next.
+// Downloads larger than MaxBytes, when positive, are cut short and rejected.
+type HTTPFetcher struct {
+	Client   *http.Client
+	Retries  int
+	Backoff  time.Duration
+	MaxBytes int64
+}
+
+// Verify at compile-time that *HTTPFetcher implements Fetcher.
+var _ Fetcher = (*HTTPFetcher)(nil)
+
+// maxRedirects bounds the redirects followed by NewHTTPFetcher clients.
+const maxRedirects = 5
+
+// NewHTTPFetcher returns an HTTPFetcher whose attempts each time out after timeout. Its
+// client refuses private addresses, and follows redirects only over HTTP(S) to hosts.
+func NewHTTPFetcher(timeout time.Duration, retries int, backoff time.Duration, hosts Hosts) *HTTPFetcher {
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: safeTransport(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			switch {
+			case len(via) >= maxRedirects:
+				return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, maxRedirects)
+			case req.URL.Scheme != "http" && req.URL.Scheme != "https":
+				return reject(req.URL.String(), ErrSchemeNotAllowed, req.URL.Scheme)
+			case !hosts.Allows(req.URL.Hostname()):
+				return reject(req.URL.String(), ErrHostNotAllowed, req.URL.Hostname())
+			default:
+				return nil
+			}
+		},
+	}
+
+	return &HTTPFetcher{Client: client, Retries: retries, Backoff: backoff}
+}
+
+// Fetch downloads rawURL, conditionally on etag when it is not empty.
+func (h *HTTPFetcher) Fetch(ctx context.Context, rawURL, etag string) (result Response, err error) {
+	ctx, span := tracing_service.Tracer().Start(ctx, "FetchLogo",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("url.full", rawURL), attribute.Bool("revalidation", etag != "")),
+	)
+	defer func() { tracing_service.End(span, err) }()
+
+	for attempt := 0; ; attempt++ {
+		result, err = h.attempt(ctx, rawURL, etag)
+		if err == nil || attempt >= h.Retries || !retryable(err) {
+			span.SetAttributes(attribute.Int("attempts", attempt+1))
+
+			return result, err
+		}
+
+		select {
+		case <-time.After(h.Backoff << attempt):
+		case <-ctx.Done():
+			return Response{}, fmt.Errorf("download %q: %w", rawURL, ctx.Err())
+		}
+	}
+}
+
+func (h *HTTPFetcher) attempt(ctx context.Context, rawURL, etag string) (Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Response{}, fmt.Errorf("create request for %q: %w", rawURL, err)
+	}
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("download %q: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return Response{NotModified: true, ETag: etag}, nil
+	default:
+		return Response{}, &StatusError{URL: rawURL, StatusCode: resp.StatusCode}
+	}
+
+	body, err := readLimited(rawURL, resp.Body, resp.ContentLength, h.MaxBytes)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return Response{Body: body, ContentType: resp.Header.Get("Content-Type"), ETag: resp.Header.Get("ETag")}, nil
+}
+
+// readLimited reads a logo of announced length, -1 if unknown, rejecting it as soon as it is
+// known to exceed maxBytes, when positive.
+func readLimited(rawURL string, body io.Reader, length, maxBytes int64) ([]byte, error) {
+	if maxBytes > 0 {
+		if length > maxBytes {
+			return nil, reject(rawURL, ErrTooLarge, fmt.Sprintf("%d bytes announced, limit %d", length, maxBytes))
+		}
+
+		body = io.LimitReader(body, maxBytes+1)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("download %q: %w", rawURL, err)
+	}
+
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, reject(rawURL, ErrTooLarge, fmt.Sprintf("more than %d bytes", maxBytes))
+	}
+
+	return data, nil
+}
+
+// retryable reports whether a failed attempt may succeed when repeated.
+func retryable(err error) bool {
+	var rejected *RejectedError
+	if errors.As(err, &rejected) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	// Cancellation is final; timeouts and connection errors are not.
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,117 @@
+package logo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DirFetcher reads logos from a local mirror: the logo at scheme://host/a/b.png is the file
+// Root/host/a/b.png, and file:///a/b.png is Root/a/b.png. Its entity tags derive from the
+// modification time and size of the file.
+type DirFetcher struct {
+	Root string
+}
+
+// Verify at compile-time that *DirFetcher implements Fetcher.
+var _ Fetcher = (*DirFetcher)(nil)
+
+// Fetch reads the file mirroring rawURL.
+func (d *DirFetcher) Fetch(_ context.Context, rawURL, etag string) (Response, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Response{}, fmt.Errorf("parsing logo URL %q: %w", rawURL, err)
+	}
+
+	// Cleaning the rooted path keeps ".." in it from leaving Root, but not a host such as
+	// "..", so the joined name must still be local to Root.
+	rel := filepath.Join(parsed.Host, filepath.FromSlash(path.Clean("/"+parsed.Path)))
+	if strings.ContainsAny(parsed.Host, `/\`) || !filepath.IsLocal(rel) {
+		return Response{}, reject(rawURL, ErrHostNotAllowed, "outside of the mirror")
+	}
+
+	name := filepath.Join(d.Root, rel)
+
+	info, err := os.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Response{}, &StatusError{URL: rawURL, StatusCode: http.StatusNotFound}
+	} else if err != nil {
+		return Response{}, fmt.Errorf("reading logo %q: %w", rawURL, err)
+	}
+
+	current := `"` + strconv.FormatInt(info.ModTime().UnixNano(), 16) + "-" + strconv.FormatInt(info.Size(), 16) + `"`
+	if etag == current {
+		return Response{NotModified: true, ETag: etag}, nil
+	}
+
+	body, err := os.ReadFile(name)
+	if err != nil {
+		return Response{}, fmt.Errorf("reading logo %q: %w", rawURL, err)
+	}
+
+	return Response{Body: body, ETag: current}, nil
+}
+
+// Fake serves logos from memory, for tests. Logos without an ETag are always sent in full.
+type Fake struct {
+	mu       sync.Mutex
+	logos    map[string]Response
+	requests map[string]int
+}
+
+// Verify at compile-time that *Fake implements Fetcher.
+var _ Fetcher = (*Fake)(nil)
+
+// NewFake returns a Fake serving logos by URL; other URLs are answered 404.
+func NewFake(logos map[string]Response) *Fake {
+	return &Fake{logos: maps.Clone(logos), requests: make(map[string]int)}
+}
+
+// Fetch returns the logo of rawURL.
+func (f *Fake) Fetch(ctx context.Context, rawURL, etag string) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, fmt.Errorf("download %q: %w", rawURL, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests[rawURL]++
+
+	logo, ok := f.logos[rawURL]
+	if !ok {
+		return Response{}, &StatusError{URL: rawURL, StatusCode: http.StatusNotFound}
+	}
+
+	if etag != "" && etag == logo.ETag {
+		return Response{NotModified: true, ETag: etag}, nil
+	}
+
+	return logo, nil
+}
+
+// Set serves logo at rawURL from now on.
+func (f *Fake) Set(rawURL string, logo Response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.logos[rawURL] = logo
+}
+
+// Requests returns how many times rawURL was fetched.
+func (f *Fake) Requests(rawURL string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests[rawURL]
+}
@@ -0,0 +1,83 @@
+package logo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Fetcher downloads logos at s3://bucket/key URLs, such as those kept in our own buckets.
+// Objects larger than MaxBytes, when positive, are rejected.
+type S3Fetcher struct {
+	Client   *s3.Client
+	MaxBytes int64
+}
+
+// Verify at compile-time that *S3Fetcher implements Fetcher.
+var _ Fetcher = (*S3Fetcher)(nil)
+
+// NewS3Fetcher returns an S3Fetcher using the default AWS configuration.
+func NewS3Fetcher(ctx context.Context) (*S3Fetcher, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithDefaultRegion("us-west-2"))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+
+	return &S3Fetcher{Client: s3.NewFromConfig(cfg)}, nil
+}
+
+// Fetch downloads the object of rawURL, conditionally on etag when it is not empty.
+func (f *S3Fetcher) Fetch(ctx context.Context, rawURL, etag string) (Response, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Response{}, fmt.Errorf("parsing logo URL %q: %w", rawURL, err)
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(parsed.Host),
+		Key:    aws.String(strings.TrimPrefix(parsed.Path, "/")),
+	}
+	if etag != "" {
+		input.IfNoneMatch = aws.String(etag)
+	}
+
+	output, err := f.Client.GetObject(ctx, input)
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return Response{}, &StatusError{URL: rawURL, StatusCode: http.StatusNotFound}
+		}
+
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) {
+			if respErr.HTTPStatusCode() == http.StatusNotModified {
+				return Response{NotModified: true, ETag: etag}, nil
+			}
+
+			return Response{}, &StatusError{URL: rawURL, StatusCode: respErr.HTTPStatusCode()}
+		}
+
+		return Response{}, fmt.Errorf("download %q: %w", rawURL, err)
+	}
+	defer output.Body.Close()
+
+	body, err := readLimited(rawURL, output.Body, aws.ToInt64(output.ContentLength), f.MaxBytes)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		Body:        body,
+		ContentType: aws.ToString(output.ContentType),
+		ETag:        aws.ToString(output.ETag),
+	}, nil
+}
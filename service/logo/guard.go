@@ -0,0 +1,242 @@
+package logo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Reasons a logo is rejected. Each comes wrapped in a RejectedError.
+var (
+	ErrSchemeNotAllowed = errors.New("logo URL scheme not allowed")
+	ErrHostNotAllowed   = errors.New("logo host not allowed")
+	ErrPrivateAddress   = errors.New("logo host is a private address")
+	ErrTooLarge         = errors.New("logo exceeds the size limit")
+	ErrNotImage         = errors.New("logo is not an allowed image type")
+	ErrBudgetExceeded   = errors.New("logo download budget exceeded")
+)
+
+// RejectedError reports a logo refused by policy rather than failing to download. Reason
+// is one of the Err*NotAllowed, ErrPrivateAddress, ErrTooLarge, ErrNotImage or
+// ErrBudgetExceeded errors, and is matched by errors.Is.
+type RejectedError struct {
+	URL    string
+	Reason error
+	Detail string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("logo %q rejected: %v: %s", e.URL, e.Reason, e.Detail)
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.Reason
+}
+
+func reject(rawURL string, reason error, detail string) *RejectedError {
+	return &RejectedError{URL: rawURL, Reason: reason, Detail: detail}
+}
+
+// Hosts is a host allow-list: "example.com" allows that host and "*.example.com" its
+// subdomains. An empty list allows any host.
+type Hosts []string
+
+// Allows reports whether host is in the list.
+func (h Hosts) Allows(host string) bool {
+	if len(h) == 0 {
+		return true
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range h {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Policy restricts the logos a Guard lets through.
+type Policy struct {
+	// MaxBytes bounds the size of a logo; 0 disables the limit.
+	MaxBytes int64
+	// Hosts restricts the URL hosts, which for s3:// URLs are buckets.
+	Hosts Hosts
+}
+
+// Guard applies Policy to the logos of Fetcher: it checks the host before fetching, then
+// the size, the sniffed content type and the budget of the context. The sniffed type
+// replaces the Content-Type claimed by the origin.
+type Guard struct {
+	Fetcher Fetcher
+	Policy  Policy
+}
+
+// Verify at compile-time that *Guard implements Fetcher.
+var _ Fetcher = (*Guard)(nil)
+
+// Fetch fetches rawURL if the policy allows it.
+func (g *Guard) Fetch(ctx context.Context, rawURL, etag string) (Response, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Response{}, fmt.Errorf("parsing logo URL %q: %w", rawURL, err)
+	}
+
+	if !g.Policy.Hosts.Allows(parsed.Hostname()) {
+		return Response{}, reject(rawURL, ErrHostNotAllowed, parsed.Hostname())
+	}
+
+	budget := budgetFrom(ctx)
+	if budget != nil && budget.remaining.Load() <= 0 {
+		return Response{}, reject(rawURL, ErrBudgetExceeded, "no bytes left")
+	}
+
+	resp, err := g.Fetcher.Fetch(ctx, rawURL, etag)
+	if err != nil || resp.NotModified {
+		return resp, err
+	}
+
+	size := int64(len(resp.Body))
+	if g.Policy.MaxBytes > 0 && size > g.Policy.MaxBytes {
+		return Response{}, reject(rawURL, ErrTooLarge, fmt.Sprintf("%d bytes, limit %d", size, g.Policy.MaxBytes))
+	}
+
+	mediaType := Sniff(resp.Body)
+	if !imageType(mediaType) {
+		return Response{}, reject(rawURL, ErrNotImage, mediaType)
+	}
+
+	resp.ContentType = mediaType
+
+	if budget != nil && budget.remaining.Add(-size) < 0 {
+		return Response{}, reject(rawURL, ErrBudgetExceeded, fmt.Sprintf("%d bytes over", -budget.remaining.Load()))
+	}
+
+	return resp, nil
+}
+
+// Sniff returns the media type of a logo from its content. SVG, which the standard sniffer
+// reports as XML or text, is recognized by its root element.
+func Sniff(body []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(body))
+
+	if mediaType == "text/xml" || mediaType == "text/plain" {
+		const sniffLen = 1024
+
+		head := body[:min(len(body), sniffLen)]
+		if bytes.Contains(bytes.ToLower(head), []byte("<svg")) {
+			return "image/svg+xml"
+		}
+	}
+
+	return mediaType
+}
+
+// imageType reports whether mediaType is an image format logos are normalized from.
+func imageType(mediaType string) bool {
+	switch mediaType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp", "image/svg+xml":
+		return true
+	default:
+		return false
+	}
+}
+
+// Budget bounds the bytes downloaded while serving one request.
+type Budget struct {
+	remaining atomic.Int64
+}
+
+type budgetKey struct{}
+
+// WithBudget returns a context whose logo downloads may total at most maxBytes. A
+// non-positive maxBytes leaves downloads unbounded.
+func WithBudget(ctx context.Context, maxBytes int64) context.Context {
+	if maxBytes <= 0 {
+		return ctx
+	}
+
+	budget := &Budget{}
+	budget.remaining.Store(maxBytes)
+
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+func budgetFrom(ctx context.Context) *Budget {
+	budget, _ := ctx.Value(budgetKey{}).(*Budget)
+
+	return budget
+}
+
+// privatePrefixes are the ranges, besides those recognized by netip, that must not be
+// reached: shared address space (RFC 6598) and IPv4 translation prefixes.
+func privatePrefixes() []netip.Prefix {
+	return []netip.Prefix{
+		netip.MustParsePrefix("100.64.0.0/10"),
+		netip.MustParsePrefix("192.0.0.0/24"),
+		netip.MustParsePrefix("198.18.0.0/15"),
+		netip.MustParsePrefix("64:ff9b::/96"),
+	}
+}
+
+// Private reports whether addr is loopback, private, link-local (such as the instance
+// metadata service), unspecified, multicast or otherwise not a public unicast address.
+func Private(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+
+	for _, prefix := range privatePrefixes() {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// denyPrivate is a net.Dialer Control function refusing private addresses. It runs after
+// name resolution, for every address tried, so DNS cannot point an allowed name inside.
+func denyPrivate(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("parsing dialed address %q: %w", address, err)
+	}
+
+	if Private(addrPort.Addr()) {
+		return reject(address, ErrPrivateAddress, addrPort.Addr().String())
+	}
+
+	return nil
+}
+
+const dialTimeout = 30 * time.Second
+
+// safeTransport returns a transport that refuses private addresses and ignores proxy
+// settings, which would otherwise bypass the check.
+func safeTransport() *http.Transport {
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: dialTimeout, Control: denyPrivate}).DialContext
+
+	return transport
+}
@@ -0,0 +1,179 @@
+package logo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"biblebrain-services/config"
+)
+
+// Entry describes a cached logo. Entries are keyed by the hash of their URL, while the
+// content is stored under its own SHA-256 Digest, so a logo whose content changes gets a
+// new file instead of overwriting one a PDF may be reading.
+type Entry struct {
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	// Ext is the image format of the content: png, jpg, gif, webp or svg.
+	Ext string `json:"ext"`
+	// ETag is the entity tag of the origin, sent as If-None-Match on revalidation.
+	ETag   string `json:"etag,omitempty"`
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+	// Width and Height are in pixels, or the view box for SVG; zero when unknown.
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	// CheckedAt is when the origin last confirmed the content.
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Logo is a cached logo ready to be rendered.
+type Logo struct {
+	Entry
+	// Path is the local file holding the content.
+	Path string
+	// Fetched reports whether the content was downloaded from the origin by this call.
+	Fetched bool
+}
+
+// Store persists logo entries and their content. It can be shared by containers, in which
+// case concurrent writers may only race to write identical content or equivalent entries.
+type Store interface {
+	// Entry returns the entry stored under the URL hash key, or ErrNotFound.
+	Entry(ctx context.Context, key string) (Entry, error)
+	PutEntry(ctx context.Context, key string, entry Entry) error
+	// Data returns the content whose SHA-256 is digest, or ErrNotFound.
+	Data(ctx context.Context, digest string) ([]byte, error)
+	PutData(ctx context.Context, digest string, data []byte) error
+}
+
+// ErrNotFound indicates that nothing is stored under the key or digest.
+var ErrNotFound = errors.New("logo not cached")
+
+// ErrInvalidKey indicates a key or digest that is not a hex SHA-256.
+var ErrInvalidKey = errors.New("invalid logo key")
+
+// ErrUnknownStore indicates that the configuration names an unsupported store.
+var ErrUnknownStore = errors.New("unknown logo store")
+
+// ErrStatus is matched by every StatusError.
+var ErrStatus = errors.New("logo download returned unexpected status")
+
+// hashPattern keeps keys and digests safe to use as file names and object keys.
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func validHash(hash string) error {
+	if !hashPattern.MatchString(hash) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, hash)
+	}
+
+	return nil
+}
+
+// Key returns the hex SHA-256 of url, under which its entry is stored.
+func Key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// New builds the cache configured by cfg.Logos, fetching logos with NewFetcher.
+func New(ctx context.Context, cfg config.Copyright) (*Cache, error) {
+	var store Store
+
+	switch cfg.Logos.Store {
+	case config.LogoStoreDisk:
+		store = &Disk{Dir: cfg.Logos.Dir}
+	case config.LogoStoreS3:
+		s3Store, err := NewS3(ctx, cfg.Logos.Bucket, cfg.Logos.Prefix, cfg.Logos.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		store = s3Store
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStore, cfg.Logos.Store)
+	}
+
+	fetcher, err := NewFetcher(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCache(store, cfg.Logos.Dir, cfg.Logos.TTL, fetcher), nil
+}
+
+// NewFetcher returns the Fetcher configured by cfg: http(s) logos are downloaded, or read
+// from cfg.Logos.Mirror when it is set, and s3:// logos are read from S3, for the allowed
+// schemes only, and all under the Guard of the configured limits and hosts. Local file URLs
+// are deliberately not supported, since logo URLs come from the database.
+func NewFetcher(ctx context.Context, cfg config.Copyright) (*Guard, error) {
+	maxBytes := int64(cfg.Logos.MaxBytes)
+	hosts := Hosts(cfg.Logos.AllowedHosts)
+
+	var web Fetcher = &DirFetcher{Root: cfg.Logos.Mirror}
+	if cfg.Logos.Mirror == "" {
+		httpFetcher := NewHTTPFetcher(cfg.DownloadTimeout, cfg.Logos.Retries, cfg.Logos.RetryBackoff, hosts)
+		httpFetcher.MaxBytes = maxBytes
+		web = httpFetcher
+	}
+
+	schemes := Schemes{}
+
+	for _, scheme := range cfg.Logos.AllowedSchemes {
+		switch scheme {
+		case "http", "https":
+			schemes[scheme] = web
+		case "s3":
+			s3Fetcher, err := NewS3Fetcher(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			s3Fetcher.MaxBytes = maxBytes
+			schemes[scheme] = s3Fetcher
+		}
+	}
+
+	return &Guard{Fetcher: schemes, Policy: Policy{MaxBytes: maxBytes, Hosts: hosts}}, nil
+}
+
+// Defaults of the process-wide cache when SetDefault was not called.
+const (
+	DefaultTTL          = 24 * time.Hour
+	DefaultTimeout      = 10 * time.Second
+	DefaultRetries      = 2
+	DefaultRetryBackoff = 200 * time.Millisecond
+	DefaultMaxBytes     = 5 << 20
+)
+
+var defaultCache atomic.Pointer[Cache]
+
+// Default returns the process-wide Cache: the one given to SetDefault, or else a disk cache
+// in the temporary directory downloading http(s) logos.
+func Default() *Cache {
+	if cache := defaultCache.Load(); cache != nil {
+		return cache
+	}
+
+	dir := filepath.Join(os.TempDir(), "copyright-logos")
+	web := NewHTTPFetcher(DefaultTimeout, DefaultRetries, DefaultRetryBackoff, nil)
+	web.MaxBytes = DefaultMaxBytes
+	fetcher := &Guard{Fetcher: Schemes{"http": web, "https": web}, Policy: Policy{MaxBytes: DefaultMaxBytes}}
+	defaultCache.CompareAndSwap(nil, NewCache(&Disk{Dir: dir}, dir, DefaultTTL, fetcher))
+
+	return defaultCache.Load()
+}
+
+// SetDefault replaces the process-wide Cache.
+func SetDefault(cache *Cache) {
+	defaultCache.Store(cache)
+}
@@ -0,0 +1,475 @@
+package logo_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logo_service "biblebrain-services/service/logo"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func pngOf(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+
+	return buf.Bytes()
+}
+
+// TestCache verifies that logos sharing a file name are kept apart, that a fresh logo is
+// not downloaded again, and that a stale one is revalidated with If-None-Match.
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	logos := map[string][]byte{
+		"/a/logo.png": pngOf(t, 3, 2),
+		"/b/logo.png": pngOf(t, 5, 4),
+	}
+
+	var downloads, revalidations atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + r.URL.Path + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			revalidations.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		downloads.Add(1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(logos[r.URL.Path])
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	fetcher := logo_service.NewHTTPFetcher(time.Second, 0, 0, nil)
+	// The test server listens on loopback, which the default client refuses.
+	fetcher.Client = server.Client()
+	cache := logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, time.Hour, fetcher)
+
+	first, err := cache.Get(t.Context(), server.URL+"/a/logo.png")
+	require.NoError(t, err)
+	require.True(t, first.Fetched)
+	require.Equal(t, "png", first.Ext)
+	require.Equal(t, "image/png", first.ContentType)
+	require.Equal(t, 3, first.Width)
+	require.Equal(t, 2, first.Height)
+
+	second, err := cache.Get(t.Context(), server.URL+"/b/logo.png")
+	require.NoError(t, err)
+	require.NotEqual(t, first.Path, second.Path)
+
+	content, err := os.ReadFile(first.Path)
+	require.NoError(t, err)
+	require.Equal(t, logos["/a/logo.png"], content)
+
+	again, err := cache.Get(t.Context(), server.URL+"/a/logo.png")
+	require.NoError(t, err)
+	require.False(t, again.Fetched)
+	require.Equal(t, first.Path, again.Path)
+	require.EqualValues(t, 2, downloads.Load())
+
+	// A new cache over the same store starts from the stored entries, stale with a zero TTL.
+	stale := logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, 0, fetcher)
+
+	revalidated, err := stale.Get(t.Context(), server.URL+"/a/logo.png")
+	require.NoError(t, err)
+	require.False(t, revalidated.Fetched)
+	require.Equal(t, first.Path, revalidated.Path)
+	require.EqualValues(t, 2, downloads.Load())
+	require.EqualValues(t, 1, revalidations.Load())
+}
+
+// TestCacheConcurrent verifies that concurrent requests for a URL share one download.
+func TestCacheConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var downloads atomic.Int32
+
+	content := pngOf(t, 1, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		downloads.Add(1)
+		<-release
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	fetcher := logo_service.NewHTTPFetcher(5*time.Second, 0, 0, nil)
+	fetcher.Client = server.Client()
+	cache := logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, time.Hour, fetcher)
+
+	var wg sync.WaitGroup
+
+	paths := make([]string, 8)
+	for i := range paths {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			logo, err := cache.Get(t.Context(), server.URL+"/logo")
+			if err == nil {
+				paths[i] = logo.Path
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.EqualValues(t, 1, downloads.Load())
+
+	for _, path := range paths {
+		require.Equal(t, paths[0], path)
+	}
+}
+
+// TestCacheErrors verifies that a failed download is an error and that keys cannot escape
+// the store directory.
+func TestCacheErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	fetcher := logo_service.NewHTTPFetcher(time.Second, 0, 0, nil)
+	fetcher.Client = server.Client()
+	cache := logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, time.Hour, fetcher)
+
+	_, err := cache.Get(t.Context(), server.URL+"/missing.png")
+	require.ErrorIs(t, err, logo_service.ErrStatus)
+
+	store := &logo_service.Disk{Dir: dir}
+	_, err = store.Entry(t.Context(), logo_service.Key(server.URL+"/missing.png"))
+	require.ErrorIs(t, err, logo_service.ErrNotFound)
+
+	require.ErrorIs(t, store.PutData(t.Context(), "../../etc/passwd", nil), logo_service.ErrInvalidKey)
+	require.Len(t, logo_service.Key("https://example.com/logo.png"), 64)
+	require.False(t, strings.ContainsAny(logo_service.Key("a/b"), "/."))
+}
+
+// TestHTTPFetcherRetries verifies that 5xx answers are retried and 404 answers are not.
+func TestHTTPFetcherRetries(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			attempts.Add(1)
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("logo"))
+	}))
+	t.Cleanup(server.Close)
+
+	fetcher := logo_service.NewHTTPFetcher(time.Second, 2, time.Millisecond, nil)
+	fetcher.Client = server.Client()
+
+	resp, err := fetcher.Fetch(t.Context(), server.URL+"/logo.png", "")
+	require.NoError(t, err)
+	require.Equal(t, "logo", string(resp.Body))
+	require.EqualValues(t, 3, attempts.Load())
+
+	attempts.Store(0)
+
+	_, err = fetcher.Fetch(t.Context(), server.URL+"/missing.png", "")
+
+	var statusErr *logo_service.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	require.True(t, statusErr.Gone())
+	require.EqualValues(t, 1, attempts.Load())
+}
+
+// TestDirFetcher verifies that logos are read from the mirror layout, revalidated by their
+// entity tag, and that paths cannot escape the root.
+func TestDirFetcher(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cdn.example.com", "logos"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cdn.example.com", "logos", "a.png"), []byte("a"), 0o600))
+
+	fetcher := &logo_service.DirFetcher{Root: root}
+
+	resp, err := fetcher.Fetch(t.Context(), "https://cdn.example.com/logos/a.png", "")
+	require.NoError(t, err)
+	require.Equal(t, "a", string(resp.Body))
+	require.NotEmpty(t, resp.ETag)
+
+	resp, err = fetcher.Fetch(t.Context(), "https://cdn.example.com/logos/a.png", resp.ETag)
+	require.NoError(t, err)
+	require.True(t, resp.NotModified)
+
+	_, err = fetcher.Fetch(t.Context(), "https://cdn.example.com/../../etc/passwd", "")
+	require.ErrorIs(t, err, logo_service.ErrStatus)
+
+	for _, escape := range []string{"http://../../etc/passwd", "http://../etc/passwd", "file:///"} {
+		_, err = fetcher.Fetch(t.Context(), escape, "")
+		require.ErrorIs(t, err, logo_service.ErrHostNotAllowed, escape)
+	}
+}
+
+// TestCacheFake verifies that a cache over a Fake works offline, dispatched by scheme, and
+// that revalidation keeps an unchanged logo, downloads a changed one and drops a gone one.
+func TestCacheFake(t *testing.T) {
+	t.Parallel()
+
+	const logoURL = "s3://logos/a.png"
+
+	fake := logo_service.NewFake(map[string]logo_service.Response{
+		logoURL: {Body: pngOf(t, 2, 2), ContentType: "image/png", ETag: `"v1"`},
+	})
+
+	dir := t.TempDir()
+	cache := logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, 0, logo_service.Schemes{"s3": fake})
+
+	logo, err := cache.Get(t.Context(), logoURL)
+	require.NoError(t, err)
+	require.True(t, logo.Fetched)
+	require.Equal(t, 2, logo.Width)
+
+	_, err = cache.Get(t.Context(), "ftp://logos/a.png")
+	require.ErrorIs(t, err, logo_service.ErrSchemeNotAllowed)
+
+	// A 304 keeps the logo without downloading it again.
+	logo, err = cache.Get(t.Context(), logoURL)
+	require.NoError(t, err)
+	require.False(t, logo.Fetched)
+	require.Equal(t, 2, fake.Requests(logoURL))
+
+	fake.Set(logoURL, logo_service.Response{Body: pngOf(t, 3, 3), ContentType: "image/png", ETag: `"v2"`})
+	logo, err = cache.Get(t.Context(), logoURL)
+	require.NoError(t, err)
+	require.True(t, logo.Fetched)
+	require.Equal(t, 3, logo.Width)
+
+	// A logo that is gone from its origin is no longer served from the cache.
+	gone := logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, 0, logo_service.NewFake(nil))
+	_, err = gone.Get(t.Context(), logoURL)
+	require.ErrorIs(t, err, logo_service.ErrStatus)
+}
+
+// TestCacheRevalidation verifies that a fresh logo is checked with its origin again under
+// WithRevalidation, picking up a logo replaced under the same URL.
+func TestCacheRevalidation(t *testing.T) {
+	t.Parallel()
+
+	const logoURL = "s3://logos/a.png"
+
+	fake := logo_service.NewFake(map[string]logo_service.Response{
+		logoURL: {Body: pngOf(t, 2, 2), ContentType: "image/png", ETag: `"v1"`},
+	})
+
+	dir := t.TempDir()
+	cache := logo_service.NewCache(&logo_service.Disk{Dir: dir}, dir, time.Hour, logo_service.Schemes{"s3": fake})
+
+	_, err := cache.Get(t.Context(), logoURL)
+	require.NoError(t, err)
+
+	fake.Set(logoURL, logo_service.Response{Body: pngOf(t, 3, 3), ContentType: "image/png", ETag: `"v2"`})
+
+	logo, err := cache.Get(t.Context(), logoURL)
+	require.NoError(t, err)
+	require.False(t, logo.Fetched)
+	require.Equal(t, 2, logo.Width)
+	require.Equal(t, 1, fake.Requests(logoURL))
+
+	logo, err = cache.Get(logo_service.WithRevalidation(t.Context()), logoURL)
+	require.NoError(t, err)
+	require.True(t, logo.Fetched)
+	require.Equal(t, 3, logo.Width)
+	require.Equal(t, 2, fake.Requests(logoURL))
+}
+
+// unreadableStore is a store whose content cannot be read, as an S3 bucket denying access.
+type unreadableStore struct {
+	*logo_service.Disk
+}
+
+func (unreadableStore) Data(context.Context, string) ([]byte, error) {
+	return nil, errAccessDenied
+}
+
+var errAccessDenied = errors.New("access denied")
+
+// TestCacheUnreadableData verifies that a logo whose cached content cannot be read is
+// downloaded again, whether its entry is fresh or revalidated.
+func TestCacheUnreadableData(t *testing.T) {
+	t.Parallel()
+
+	const logoURL = "s3://logos/a.png"
+
+	fake := logo_service.NewFake(map[string]logo_service.Response{
+		logoURL: {Body: pngOf(t, 2, 2), ContentType: "image/png", ETag: `"v1"`},
+	})
+	store := &logo_service.Disk{Dir: t.TempDir()}
+
+	_, err := logo_service.NewCache(store, t.TempDir(), time.Hour, logo_service.Schemes{"s3": fake}).
+		Get(t.Context(), logoURL)
+	require.NoError(t, err)
+
+	// A fresh entry is downloaded again; a stale one is revalidated, then downloaded again.
+	for _, tc := range []struct {
+		ttl      time.Duration
+		requests int
+	}{{time.Hour, 2}, {0, 4}} {
+		// Without a local copy, the content must be read from the store.
+		cache := logo_service.NewCache(unreadableStore{store}, t.TempDir(), tc.ttl, logo_service.Schemes{"s3": fake})
+
+		logo, err := cache.Get(t.Context(), logoURL)
+		require.NoError(t, err)
+		require.True(t, logo.Fetched)
+		require.Equal(t, 2, logo.Width)
+		require.Equal(t, tc.requests, fake.Requests(logoURL))
+	}
+}
+
+// TestS3Missing verifies that the S3 store reports a missing key as ErrNotFound, including
+// the 403 S3 answers to callers without s3:ListBucket, and other failures as errors.
+func TestS3Missing(t *testing.T) {
+	t.Parallel()
+
+	status := map[string]int{
+		"missing": http.StatusNotFound,
+		"denied":  http.StatusForbidden,
+		"down":    http.StatusBadGateway,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status[strings.Split(r.URL.Path, "/")[1]])
+	}))
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		Region:           "us-west-2",
+		BaseEndpoint:     aws.String(server.URL),
+		UsePathStyle:     true,
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+	digest := logo_service.Key("logo")
+
+	for _, bucket := range []string{"missing", "denied"} {
+		_, err := (&logo_service.S3{Client: client, Bucket: bucket}).Data(t.Context(), digest)
+		require.ErrorIs(t, err, logo_service.ErrNotFound, bucket)
+	}
+
+	_, err := (&logo_service.S3{Client: client, Bucket: "down"}).Data(t.Context(), digest)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, logo_service.ErrNotFound)
+}
+
+// TestGuard verifies that each policy violation is rejected with its own reason.
+func TestGuard(t *testing.T) {
+	t.Parallel()
+
+	logo := pngOf(t, 2, 2)
+	fake := logo_service.NewFake(map[string]logo_service.Response{
+		"https://cdn.example.com/logo.png": {Body: logo, ContentType: "application/octet-stream"},
+		"https://cdn.example.com/big.png":  {Body: append(pngOf(t, 2, 2), make([]byte, 1024)...)},
+		"https://cdn.example.com/page.png": {Body: []byte("<html><body>login</body></html>"), ContentType: "image/png"},
+		"https://cdn.example.com/logo.svg": {Body: []byte(`<?xml version="1.0"?><svg viewBox="0 0 1 1"/>`)},
+		"https://evil.example.org/a.png":   {Body: logo},
+	})
+	guard := &logo_service.Guard{
+		Fetcher: logo_service.Schemes{"https": fake},
+		Policy:  logo_service.Policy{MaxBytes: int64(len(logo)) + 512, Hosts: logo_service.Hosts{"*.example.com"}},
+	}
+
+	resp, err := guard.Fetch(t.Context(), "https://cdn.example.com/logo.png", "")
+	require.NoError(t, err)
+	require.Equal(t, "image/png", resp.ContentType, "the sniffed type replaces the claimed one")
+
+	resp, err = guard.Fetch(t.Context(), "https://cdn.example.com/logo.svg", "")
+	require.NoError(t, err)
+	require.Equal(t, "image/svg+xml", resp.ContentType)
+
+	for url, reason := range map[string]error{
+		"https://cdn.example.com/big.png":  logo_service.ErrTooLarge,
+		"https://cdn.example.com/page.png": logo_service.ErrNotImage,
+		"https://evil.example.org/a.png":   logo_service.ErrHostNotAllowed,
+		"ftp://cdn.example.com/logo.png":   logo_service.ErrSchemeNotAllowed,
+	} {
+		_, err = guard.Fetch(t.Context(), url, "")
+		require.ErrorIs(t, err, reason, url)
+
+		var rejected *logo_service.RejectedError
+		require.ErrorAs(t, err, &rejected, url)
+		require.Equal(t, url, rejected.URL)
+	}
+
+	require.Zero(t, fake.Requests("https://evil.example.org/a.png"), "disallowed hosts are not contacted")
+
+	// The budget admits the first logo, then refuses the one going over and everything after.
+	ctx := logo_service.WithBudget(t.Context(), int64(len(logo))+1)
+	_, err = guard.Fetch(ctx, "https://cdn.example.com/logo.png", "")
+	require.NoError(t, err)
+	_, err = guard.Fetch(ctx, "https://cdn.example.com/logo.png", "")
+	require.ErrorIs(t, err, logo_service.ErrBudgetExceeded)
+
+	requests := fake.Requests("https://cdn.example.com/logo.png")
+	_, err = guard.Fetch(ctx, "https://cdn.example.com/logo.png", "")
+	require.ErrorIs(t, err, logo_service.ErrBudgetExceeded)
+	require.Equal(t, requests, fake.Requests("https://cdn.example.com/logo.png"))
+}
+
+// TestHTTPFetcherLimits verifies that the default client refuses private addresses and
+// that oversized downloads are cut short.
+func TestHTTPFetcherLimits(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(make([]byte, 4096))
+	}))
+	t.Cleanup(server.Close)
+
+	fetcher := logo_service.NewHTTPFetcher(time.Second, 2, time.Millisecond, nil)
+	_, err := fetcher.Fetch(t.Context(), server.URL+"/logo.png", "")
+	require.ErrorIs(t, err, logo_service.ErrPrivateAddress)
+
+	fetcher.Client = server.Client()
+	fetcher.MaxBytes = 1024
+	_, err = fetcher.Fetch(t.Context(), server.URL+"/logo.png", "")
+	require.ErrorIs(t, err, logo_service.ErrTooLarge)
+
+	require.True(t, logo_service.Private(netip.MustParseAddr("169.254.169.254")))
+	require.True(t, logo_service.Private(netip.MustParseAddr("::ffff:10.0.0.1")))
+	require.False(t, logo_service.Private(netip.MustParseAddr("93.184.216.34")))
+	require.True(t, logo_service.Hosts{"*.example.com"}.Allows("CDN.Example.com."))
+	require.False(t, logo_service.Hosts{"*.example.com"}.Allows("example.com.evil.org"))
+}
@@ -0,0 +1,152 @@
+package logo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3 keeps entries as Prefix+"entries/"+key+".json" and content as Prefix+"data/"+digest in
+// Bucket of S3 or an S3-compatible store, so that all containers share the logos. Content
+// no longer referenced by an entry is left to a lifecycle rule on the bucket.
+type S3 struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+// Verify at compile-time that *S3 implements Store.
+var _ Store = (*S3)(nil)
+
+// NewS3 returns an S3 store using the default AWS configuration. A non-empty endpoint
+// selects an S3-compatible store, addressed with path-style URLs.
+func NewS3(ctx context.Context, bucket, prefix, endpoint string) (*S3, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithDefaultRegion("us-west-2"))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(options *s3.Options) {
+		if endpoint != "" {
+			options.BaseEndpoint = aws.String(endpoint)
+			options.UsePathStyle = true
+		}
+	})
+
+	return &S3{Client: client, Bucket: bucket, Prefix: prefix}, nil
+}
+
+// Entry downloads the entry stored under key.
+func (s *S3) Entry(ctx context.Context, key string) (Entry, error) {
+	if err := validHash(key); err != nil {
+		return Entry{}, err
+	}
+
+	raw, err := s.get(ctx, s.Prefix+"entries/"+key+".json")
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return Entry{}, fmt.Errorf("decoding logo entry %s: %w", key, err)
+	}
+
+	return entry, nil
+}
+
+// PutEntry uploads entry under key.
+func (s *S3) PutEntry(ctx context.Context, key string, entry Entry) error {
+	if err := validHash(key); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding logo entry %s: %w", key, err)
+	}
+
+	return s.put(ctx, s.Prefix+"entries/"+key+".json", raw, "application/json")
+}
+
+// Data downloads the content whose SHA-256 is digest.
+func (s *S3) Data(ctx context.Context, digest string) ([]byte, error) {
+	if err := validHash(digest); err != nil {
+		return nil, err
+	}
+
+	return s.get(ctx, s.Prefix+"data/"+digest)
+}
+
+// PutData uploads data under digest.
+func (s *S3) PutData(ctx context.Context, digest string, data []byte) error {
+	if err := validHash(digest); err != nil {
+		return err
+	}
+
+	return s.put(ctx, s.Prefix+"data/"+digest, data, "application/octet-stream")
+}
+
+func (s *S3) get(ctx context.Context, key string) ([]byte, error) {
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if missing(err) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("downloading %s: %w", key, err)
+	}
+	defer output.Body.Close()
+
+	value, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// missing reports whether err is S3 answering that a key does not exist: NoSuchKey, 404, or
+// 403, which is how S3 answers a missing key to callers without s3:ListBucket.
+func missing(err error) bool {
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+
+	status := respErr.HTTPStatusCode()
+
+	return status == http.StatusNotFound || status == http.StatusForbidden
+}
+
+func (s *S3) put(ctx context.Context, key string, value []byte, contentType string) error {
+	_, err := s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(value),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading %s: %w", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CloudWatch units used by the EMF recorder.
+const (
+	unitCount        = "Count"
+	unitMilliseconds = "Milliseconds"
+	unitBytes        = "Bytes"
+)
+
+// EMF is a Recorder that writes every measurement as a CloudWatch Embedded Metric Format
+// JSON line. In Lambda, lines written to stdout are turned into metrics by CloudWatch Logs
+// without any API call.
+type EMF struct {
+	mu        sync.Mutex
+	w         io.Writer
+	namespace string
+	now       func() time.Time
+}
+
+// NewEMF returns an EMF recorder writing to w under the given CloudWatch namespace.
+func NewEMF(w io.Writer, namespace string) *EMF {
+	return NewEMFWithClock(w, namespace, time.Now)
+}
+
+// NewEMFWithClock is NewEMF with an explicit clock, for tests.
+func NewEMFWithClock(w io.Writer, namespace string, now func() time.Time) *EMF {
+	return &EMF{w: w, namespace: namespace, now: now}
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+func (e *EMF) ObserveRequest(req Request, duration time.Duration) {
+	e.emit(
+		[]string{"Route", "Format", "Mode"},
+		[]emfMetric{{"Requests", unitCount}, {"RequestLatency", unitMilliseconds}},
+		map[string]any{
+			"Route":          req.Route,
+			"Format":         req.Format,
+			"Mode":           req.Mode,
+			"Status":         strconv.Itoa(req.Status),
+			"Requests":       1,
+			"RequestLatency": milliseconds(duration),
+		},
+	)
+}
+
+func (e *EMF) ObserveQuery(query string, duration time.Duration) {
+	e.emit(
+		[]string{"Query"},
+		[]emfMetric{{"QueryLatency", unitMilliseconds}},
+		map[string]any{"Query": query, "QueryLatency": milliseconds(duration)},
+	)
+}
+
+func (e *EMF) AddLogos(outcome LogoOutcome, count int) {
+	if count == 0 {
+		return
+	}
+
+	e.emit(
+		[]string{"Outcome"},
+		[]emfMetric{{"Logos", unitCount}},
+		map[string]any{"Outcome": string(outcome), "Logos": count},
+	)
+}
+
+func (e *EMF) ObservePDF(pages int, bytes int64) {
+	e.emit(
+		[]string{},
+		[]emfMetric{{"PDFPages", unitCount}, {"PDFBytes", unitBytes}},
+		map[string]any{"PDFPages": pages, "PDFBytes": bytes},
+	)
+}
+
+// emit writes one EMF line with the given dimension keys, metric definitions and values.
+func (e *EMF) emit(dimensions []string, metrics []emfMetric, fields map[string]any) {
+	fields["_aws"] = emfMetadata{
+		Timestamp: e.now().UnixMilli(),
+		CloudWatchMetrics: []emfDirective{{
+			Namespace:  e.namespace,
+			Dimensions: [][]string{dimensions},
+			Metrics:    metrics,
+		}},
+	}
+
+	line, err := json.Marshal(fields)
+	if err != nil {
+		slog.Warn("encoding EMF metric", "error", err)
+
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.w.Write(append(line, '\n')); err != nil {
+		slog.Warn("writing EMF metric", "error", err)
+	}
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
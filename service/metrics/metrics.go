@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultNamespace is the CloudWatch namespace and Prometheus prefix used when none is configured.
+const DefaultNamespace = "BibleBrainServices"
+
+// LogoOutcome classifies what happened to an organization logo needed for a PDF.
+type LogoOutcome string
+
+const (
+	LogoDownloaded LogoOutcome = "downloaded"
+	LogoFailed     LogoOutcome = "failed"
+	LogoCached     LogoOutcome = "cached"
+)
+
+// Request describes one handled HTTP request. Format and Mode are empty when the request
+// did not specify them.
+type Request struct {
+	Route  string
+	Format string
+	Mode   string
+	Status int
+}
+
+// Recorder records application measurements.
+type Recorder interface {
+	// ObserveRequest records one handled request and its latency.
+	ObserveRequest(req Request, duration time.Duration)
+	// ObserveQuery records the latency of one named database query.
+	ObserveQuery(query string, duration time.Duration)
+	// AddLogos counts logos by outcome.
+	AddLogos(outcome LogoOutcome, count int)
+	// ObservePDF records the page count and size of a generated PDF.
+	ObservePDF(pages int, bytes int64)
+}
+
+// Nop is a Recorder that discards every measurement.
+type Nop struct{}
+
+func (Nop) ObserveRequest(Request, time.Duration) {}
+func (Nop) ObserveQuery(string, time.Duration)    {}
+func (Nop) AddLogos(LogoOutcome, int)             {}
+func (Nop) ObservePDF(int, int64)                 {}
+
+type recorderHolder struct {
+	Recorder
+}
+
+var defaultRecorder atomic.Pointer[recorderHolder]
+
+// Default returns the process-wide Recorder, Nop unless SetDefault was called.
+func Default() Recorder {
+	if holder := defaultRecorder.Load(); holder != nil {
+		return holder.Recorder
+	}
+
+	return Nop{}
+}
+
+// SetDefault makes recorder the process-wide Recorder returned by Default.
+func SetDefault(recorder Recorder) {
+	defaultRecorder.Store(&recorderHolder{recorder})
+}
@@ -0,0 +1,72 @@
+package metrics_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metrics_service "biblebrain-services/service/metrics"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEMFRequest verifies the Embedded Metric Format envelope of a request measurement.
+func TestEMFRequest(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	now := time.UnixMilli(1700000000000)
+	recorder := metrics_service.NewEMFWithClock(&buf, "Test", func() time.Time { return now })
+
+	recorder.ObserveRequest(metrics_service.Request{
+		Route: "/api/copyright", Format: "pdf", Mode: "audio", Status: http.StatusOK,
+	}, 1500*time.Millisecond)
+
+	var line struct {
+		AWS struct {
+			Timestamp         int64
+			CloudWatchMetrics []struct {
+				Namespace  string
+				Dimensions [][]string
+				Metrics    []struct{ Name, Unit string }
+			}
+		} `json:"_aws"`
+		Route          string
+		Format         string
+		Mode           string
+		Requests       int
+		RequestLatency float64
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, int64(1700000000000), line.AWS.Timestamp)
+	require.Len(t, line.AWS.CloudWatchMetrics, 1)
+	require.Equal(t, "Test", line.AWS.CloudWatchMetrics[0].Namespace)
+	require.Equal(t, [][]string{{"Route", "Format", "Mode"}}, line.AWS.CloudWatchMetrics[0].Dimensions)
+	require.Equal(t, "/api/copyright", line.Route)
+	require.Equal(t, 1, line.Requests)
+	require.InDelta(t, 1500.0, line.RequestLatency, 0.001)
+}
+
+// TestPrometheusHandler verifies that recorded measurements are exposed with the namespace prefix.
+func TestPrometheusHandler(t *testing.T) {
+	t.Parallel()
+
+	recorder := metrics_service.NewPrometheus("BibleBrainServices")
+	recorder.ObserveQuery("GetOrganizations", 20*time.Millisecond)
+	recorder.AddLogos(metrics_service.LogoFailed, 2)
+	recorder.ObservePDF(3, 120_000)
+
+	rec := httptest.NewRecorder()
+	recorder.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `bible_brain_services_db_query_duration_seconds_count{query="GetOrganizations"} 1`)
+	require.Contains(t, string(body), `bible_brain_services_copyright_logos_total{outcome="failed"} 2`)
+	require.Contains(t, string(body), `bible_brain_services_copyright_pdf_pages_sum 3`)
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus is a Recorder backed by Prometheus collectors, exposed through Handler.
+type Prometheus struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	logos           *prometheus.CounterVec
+	pdfPages        prometheus.Histogram
+	pdfBytes        prometheus.Histogram
+}
+
+// NewPrometheus returns a Prometheus recorder whose metric names are prefixed with the
+// snake_case form of namespace, on its own registry with the Go and process collectors.
+func NewPrometheus(namespace string) *Prometheus {
+	namespace = snakeCase(namespace)
+
+	p := &Prometheus{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Handled HTTP requests by route, format, mode and status.",
+		}, []string{"route", "format", "mode", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, format and mode.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"route", "format", "mode"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query latency by query name.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"query"}),
+		logos: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "copyright_logos_total",
+			Help:      "Organization logos by outcome: downloaded, failed or cached.",
+		}, []string{"outcome"}),
+		pdfPages: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "copyright_pdf_pages",
+			Help:      "Page count of generated copyright PDFs.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+		}),
+		pdfBytes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "copyright_pdf_bytes",
+			Help:      "Size of generated copyright PDFs in bytes.",
+			Buckets:   prometheus.ExponentialBuckets(64<<10, 2, 8),
+		}),
+	}
+
+	p.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		p.requests, p.requestDuration, p.queryDuration, p.logos, p.pdfPages, p.pdfBytes,
+	)
+
+	return p
+}
+
+// Handler serves the metrics in the Prometheus text exposition format.
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+func (p *Prometheus) ObserveRequest(req Request, duration time.Duration) {
+	p.requests.WithLabelValues(req.Route, req.Format, req.Mode, strconv.Itoa(req.Status)).Inc()
+	p.requestDuration.WithLabelValues(req.Route, req.Format, req.Mode).Observe(duration.Seconds())
+}
+
+func (p *Prometheus) ObserveQuery(query string, duration time.Duration) {
+	p.queryDuration.WithLabelValues(query).Observe(duration.Seconds())
+}
+
+func (p *Prometheus) AddLogos(outcome LogoOutcome, count int) {
+	p.logos.WithLabelValues(string(outcome)).Add(float64(count))
+}
+
+func (p *Prometheus) ObservePDF(pages int, bytes int64) {
+	p.pdfPages.Observe(float64(pages))
+	p.pdfBytes.Observe(float64(bytes))
+}
+
+// snakeCase turns a CamelCase namespace such as BibleBrainServices into bible_brain_services.
+func snakeCase(name string) string {
+	var out strings.Builder
+
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				out.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		out.WriteRune(r)
+	}
+
+	return out.String()
+}
@@ -30,10 +30,22 @@ type Options struct {
 	CellHeight   float64
 }
 
+// Supported page sizes.
+const (
+	PageSizeA4     = "A4"
+	PageSizeLetter = "Letter"
+)
+
+// Configuration returns the default A4 layout.
 func Configuration() Options {
+	return ConfigurationFor(PageSizeA4)
+}
+
+// ConfigurationFor returns the layout for the given page size; anything but Letter is A4.
+func ConfigurationFor(pageSize string) Options {
 	config := Options{
-		PageDimensions: "A4", // A4, Letter, etc.
-		PageLayout:     "P",  // L=Landscape, P=Portrait
+		PageDimensions: pageSize, // A4, Letter, etc.
+		PageLayout:     "P",      // L=Landscape, P=Portrait
 		FontFamily:     "Arial",
 
 		FontSize:  8,
@@ -45,10 +57,11 @@ func Configuration() Options {
 
 	config.PageMargin = 8 // combined top and bottom margins
 
-	if config.PageDimensions == "Letter" {
+	if config.PageDimensions == PageSizeLetter {
 		config.PageWidth = 215.9
 		config.PageHeight = 279.4
 	} else { // assume A4
+		config.PageDimensions = PageSizeA4
 		config.PageWidth = 210.0
 		config.PageHeight = 297.0
 	}
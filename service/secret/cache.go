@@ -0,0 +1,96 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// CachedProvider caches the values of another provider in process for TTL.
+// When a refresh fails, the last known value is served so that a transient backend
+// error does not take the service down; call Refresh to force a re-fetch after rotation.
+type CachedProvider struct {
+	inner   Provider
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// Verify at compile-time that *CachedProvider implements Provider and Refresher.
+var (
+	_ Provider  = (*CachedProvider)(nil)
+	_ Refresher = (*CachedProvider)(nil)
+)
+
+// NewCachedProvider wraps inner with a cache of the given TTL.
+func NewCachedProvider(inner Provider, ttl time.Duration) *CachedProvider {
+	return NewCachedProviderWithClock(inner, ttl, time.Now)
+}
+
+// NewCachedProviderWithClock is NewCachedProvider with an injectable clock.
+func NewCachedProviderWithClock(inner Provider, ttl time.Duration, now func() time.Time) *CachedProvider {
+	return &CachedProvider{inner: inner, ttl: ttl, now: now, entries: make(map[string]cacheEntry)}
+}
+
+// GetSecret returns the cached value of name, fetching it when missing or older than the TTL.
+func (c *CachedProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+
+	if ok && c.now().Sub(entry.fetchedAt) < c.ttl {
+		return entry.value, nil
+	}
+
+	value, err := c.fetch(ctx, name)
+	if err != nil {
+		if ok {
+			slog.Warn("secret refresh failed, serving cached value", "secret", name, "error", err)
+
+			return entry.value, nil
+		}
+
+		return "", err
+	}
+
+	return value, nil
+}
+
+// Refresh re-fetches name from the backend, bypassing the cache.
+func (c *CachedProvider) Refresh(ctx context.Context, name string) (string, error) {
+	return c.fetch(ctx, name)
+}
+
+// Invalidate drops name from the cache.
+func (c *CachedProvider) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, name)
+}
+
+func (c *CachedProvider) fetch(ctx context.Context, name string) (string, error) {
+	value, err := c.inner.GetSecret(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("resolving secret %s: %w", name, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, ok := c.entries[name]; ok && previous.value != value {
+		slog.Info("secret value changed", "secret", name)
+	}
+
+	c.entries[name] = cacheEntry{value: value, fetchedAt: c.now()}
+
+	return value, nil
+}
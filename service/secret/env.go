@@ -0,0 +1,20 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// EnvProvider reads secrets from environment variables.
+type EnvProvider struct{}
+
+// GetSecret returns the value of the environment variable name.
+func (EnvProvider) GetSecret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: environment variable %s", ErrNotFound, name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,101 @@
+package secret
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileProvider reads secrets from the local filesystem, which makes it possible to run
+// everything offline. If Path is a directory, each secret is the content of the file with
+// the secret's name; otherwise Path is parsed as a dotenv file of KEY=VALUE lines.
+// The file is re-read on every call so that edits are picked up on the next refresh.
+type FileProvider struct {
+	Path string
+}
+
+// GetSecret returns the value stored under name.
+func (p FileProvider) GetSecret(_ context.Context, name string) (string, error) {
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	if info.IsDir() {
+		// Secret names such as SSM paths may contain slashes; keep them inside Path.
+		file := filepath.Join(p.Path, filepath.Clean("/"+name))
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("%w: %s", ErrNotFound, file)
+			}
+
+			return "", fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+
+		value := strings.TrimSpace(string(content))
+		if value == "" {
+			return "", fmt.Errorf("%w: %s is empty", ErrNotFound, file)
+		}
+
+		return value, nil
+	}
+
+	values, err := ParseDotenv(p.Path)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := values[name]
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: %s in %s", ErrNotFound, name, p.Path)
+	}
+
+	return value, nil
+}
+
+// ParseDotenv parses a dotenv file. Blank lines and lines starting with # are ignored,
+// an optional "export " prefix is stripped, and values may be wrapped in single or double quotes.
+func ParseDotenv(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: reading %s: %w", ErrUnavailable, path, err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,57 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"biblebrain-services/config"
+)
+
+// Provider is the SecretProvider abstraction: it resolves a named secret to its current value.
+// The meaning of name depends on the backend (an environment variable, a dotenv key, an SSM
+// parameter name or a Secrets Manager secret ID).
+type Provider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// Refresher is implemented by providers that cache values and can be told to re-fetch one,
+// for example after the database rejects a rotated password.
+type Refresher interface {
+	Refresh(ctx context.Context, name string) (string, error)
+}
+
+// ErrNotFound indicates that the secret does not exist or is empty.
+var ErrNotFound = errors.New("secret not found")
+
+// ErrUnavailable indicates that the backend could not be reached or returned an error.
+var ErrUnavailable = errors.New("secret backend unavailable")
+
+// ErrUnknownProvider indicates that the configuration names an unsupported backend.
+var ErrUnknownProvider = errors.New("unknown secret provider")
+
+// New builds an uncached provider for cfg.Provider.
+func New(ctx context.Context, cfg config.Secrets) (Provider, error) {
+	switch cfg.Provider {
+	case config.SecretProviderEnv:
+		return EnvProvider{}, nil
+	case config.SecretProviderFile:
+		return FileProvider{Path: cfg.File}, nil
+	case config.SecretProviderSSM:
+		return NewSSMProvider(ctx)
+	case config.SecretProviderSecretsManager:
+		return NewSecretsManagerProvider(ctx)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, cfg.Provider)
+	}
+}
+
+// NewCached builds the provider for cfg.Provider wrapped in a cache of cfg.CacheTTL.
+func NewCached(ctx context.Context, cfg config.Secrets) (*CachedProvider, error) {
+	provider, err := New(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCachedProvider(provider, cfg.CacheTTL), nil
+}
@@ -0,0 +1,130 @@
+package secret_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secret_service "biblebrain-services/service/secret"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errBackendDown = errors.New("backend down")
+
+// TestFileProviderDotenv verifies dotenv parsing: comments, export prefixes and quotes.
+func TestFileProviderDotenv(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# local settings\n" +
+		"export BIBLEBRAIN_DSN=\"user:pw@tcp(localhost:3306)/dbp\"\n" +
+		"LOG_LEVEL='Debug'\n" +
+		"EMPTY=\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	provider := secret_service.FileProvider{Path: path}
+
+	value, err := provider.GetSecret(t.Context(), "BIBLEBRAIN_DSN")
+	require.NoError(t, err)
+	require.Equal(t, "user:pw@tcp(localhost:3306)/dbp", value)
+
+	value, err = provider.GetSecret(t.Context(), "LOG_LEVEL")
+	require.NoError(t, err)
+	require.Equal(t, "Debug", value)
+
+	_, err = provider.GetSecret(t.Context(), "EMPTY")
+	require.ErrorIs(t, err, secret_service.ErrNotFound)
+
+	_, err = provider.GetSecret(t.Context(), "MISSING")
+	require.ErrorIs(t, err, secret_service.ErrNotFound)
+}
+
+// TestFileProviderDirectory verifies that a directory is read as one file per secret.
+func TestFileProviderDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dev", "rds"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dev", "rds", "DSN"), []byte("dsn-value\n"), 0o600))
+
+	provider := secret_service.FileProvider{Path: dir}
+
+	value, err := provider.GetSecret(t.Context(), "/dev/rds/DSN")
+	require.NoError(t, err)
+	require.Equal(t, "dsn-value", value)
+
+	_, err = provider.GetSecret(t.Context(), "/dev/rds/missing")
+	require.ErrorIs(t, err, secret_service.ErrNotFound)
+}
+
+// TestEnvProvider verifies that unset variables are reported as ErrNotFound.
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("SECRET_TEST_VALUE", "from-env")
+
+	value, err := secret_service.EnvProvider{}.GetSecret(t.Context(), "SECRET_TEST_VALUE")
+	require.NoError(t, err)
+	require.Equal(t, "from-env", value)
+
+	_, err = secret_service.EnvProvider{}.GetSecret(t.Context(), "SECRET_TEST_UNSET")
+	require.ErrorIs(t, err, secret_service.ErrNotFound)
+}
+
+// countingProvider returns a value that changes on every call, or fails while failing is set.
+type countingProvider struct {
+	calls   atomic.Int32
+	failing atomic.Bool
+}
+
+func (p *countingProvider) GetSecret(context.Context, string) (string, error) {
+	if p.failing.Load() {
+		return "", errBackendDown
+	}
+
+	return "v" + string(rune('0'+p.calls.Add(1))), nil
+}
+
+// TestCachedProvider verifies TTL expiry, forced refresh and serving stale values on failure.
+func TestCachedProvider(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	inner := &countingProvider{}
+	cache := secret_service.NewCachedProviderWithClock(inner, time.Minute, func() time.Time { return now })
+
+	value, err := cache.GetSecret(t.Context(), "dsn")
+	require.NoError(t, err)
+	require.Equal(t, "v1", value)
+
+	// Within the TTL the backend is not called again.
+	value, err = cache.GetSecret(t.Context(), "dsn")
+	require.NoError(t, err)
+	require.Equal(t, "v1", value)
+	require.Equal(t, int32(1), inner.calls.Load())
+
+	// After the TTL the value is re-fetched.
+	now = now.Add(2 * time.Minute)
+	value, err = cache.GetSecret(t.Context(), "dsn")
+	require.NoError(t, err)
+	require.Equal(t, "v2", value)
+
+	// Refresh bypasses the TTL, e.g. after a password rotation.
+	value, err = cache.Refresh(t.Context(), "dsn")
+	require.NoError(t, err)
+	require.Equal(t, "v3", value)
+
+	// A failing backend serves the last known value once the TTL expires...
+	inner.failing.Store(true)
+	now = now.Add(2 * time.Minute)
+	value, err = cache.GetSecret(t.Context(), "dsn")
+	require.NoError(t, err)
+	require.Equal(t, "v3", value)
+
+	// ...but not for secrets it has never resolved.
+	_, err = cache.GetSecret(t.Context(), "other")
+	require.ErrorIs(t, err, errBackendDown)
+}
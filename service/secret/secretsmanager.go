@@ -0,0 +1,51 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// SecretsManagerProvider reads secrets from AWS Secrets Manager; names are secret IDs or ARNs.
+// It always reads the AWSCURRENT version, so a refresh after rotation returns the new value.
+type SecretsManagerProvider struct {
+	Client *secretsmanager.Client
+}
+
+// NewSecretsManagerProvider returns a SecretsManagerProvider using the default AWS configuration.
+func NewSecretsManagerProvider(ctx context.Context) (*SecretsManagerProvider, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithDefaultRegion("us-west-2"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: loading AWS configuration: %w", ErrUnavailable, err)
+	}
+
+	return &SecretsManagerProvider{Client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+// GetSecret returns the string value of the current version of the secret name.
+func (p *SecretsManagerProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	output, err := p.Client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(name),
+		VersionStage: aws.String("AWSCURRENT"),
+	})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("%w: Secrets Manager secret %s", ErrNotFound, name)
+		}
+
+		return "", fmt.Errorf("%w: Secrets Manager secret %s: %w", ErrUnavailable, name, err)
+	}
+
+	value := aws.ToString(output.SecretString)
+	if value == "" {
+		return "", fmt.Errorf("%w: Secrets Manager secret %s has no string value", ErrNotFound, name)
+	}
+
+	return value, nil
+}
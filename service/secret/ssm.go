@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+
+	service_sign "biblebrain-services/service/sign"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// SSMProvider reads secrets from SSM Parameter Store; names are parameter names.
+type SSMProvider struct {
+	Client *ssm.Client
+}
+
+// NewSSMProvider returns an SSMProvider using the default AWS configuration.
+func NewSSMProvider(ctx context.Context) (*SSMProvider, error) {
+	client, err := service_sign.GetSSMClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return &SSMProvider{Client: client}, nil
+}
+
+// GetSecret returns the decrypted value of the SSM parameter name.
+func (p *SSMProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	value, err := service_sign.GetSsmParameter(ctx, p.Client, name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	if *value == "" {
+		return "", fmt.Errorf("%w: SSM parameter %s", ErrNotFound, name)
+	}
+
+	return *value, nil
+}
@@ -2,13 +2,20 @@ package sign
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 )
 
-func GetSsmParameter(ctx context.Context, ssmClient *ssm.Client, parameterName string) *string {
+// ErrParameterUnavailable indicates that an SSM parameter could not be read.
+var ErrParameterUnavailable = errors.New("unable to retrieve value from SSM")
+
+// ErrAWSConfig indicates that the AWS SDK configuration could not be loaded.
+var ErrAWSConfig = errors.New("unable to load AWS configuration")
+
+func GetSsmParameter(ctx context.Context, ssmClient *ssm.Client, parameterName string) (*string, error) {
 	input := &ssm.GetParameterInput{
 		Name:           &parameterName,
 		WithDecryption: NewTrue(),
@@ -16,10 +23,14 @@ func GetSsmParameter(ctx context.Context, ssmClient *ssm.Client, parameterName s
 
 	output, err := ssmClient.GetParameter(ctx, input)
 	if err != nil {
-		log.Panicf("unable to retrieve value from SSM at parameter name: %s, error: %s", parameterName, err.Error())
+		return nil, fmt.Errorf("%w at parameter name: %s: %w", ErrParameterUnavailable, parameterName, err)
+	}
+
+	if output.Parameter == nil || output.Parameter.Value == nil {
+		return nil, fmt.Errorf("%w at parameter name: %s: empty value", ErrParameterUnavailable, parameterName)
 	}
 
-	return output.Parameter.Value
+	return output.Parameter.Value, nil
 }
 
 func NewTrue() *bool {
@@ -28,15 +39,15 @@ func NewTrue() *bool {
 	return &b
 }
 
-func GetSSMClient(ctx context.Context) *ssm.Client {
+func GetSSMClient(ctx context.Context) (*ssm.Client, error) {
 	// NOTE: typically, if IS_OFFLINE is true, we would configure a local endpoint for the service.
 	// However, it does not appear that serverless_offline_ssm exposes an endpoint.
 	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithDefaultRegion("us-west-2"),
 	)
 	if err != nil {
-		log.Panic(err)
+		return nil, fmt.Errorf("%w: %w", ErrAWSConfig, err)
 	}
 
-	return ssm.NewFromConfig(cfg)
+	return ssm.NewFromConfig(cfg), nil
 }
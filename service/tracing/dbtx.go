@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	sqlc "biblebrain-services/sqlc/generated"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryNamePrefix starts every query generated by sqlc, e.g. "-- name: GetOrganizations :many".
+const queryNamePrefix = "-- name: "
+
+type tracedDBTX struct {
+	db sqlc.DBTX
+}
+
+// WrapDBTX returns a sqlc.DBTX that opens a span named after the sqlc query for every call.
+// The span covers executing the query; scanning the returned rows is not included. Errors
+// are returned unchanged.
+func WrapDBTX(db sqlc.DBTX) sqlc.DBTX {
+	return tracedDBTX{db: db}
+}
+
+func (t tracedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	result, err := t.db.ExecContext(ctx, query, args...)
+	End(span, err)
+
+	return result, err
+}
+
+func (t tracedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := startQuery(ctx, query)
+	stmt, err := t.db.PrepareContext(ctx, query)
+	End(span, err)
+
+	return stmt, err
+}
+
+func (t tracedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	End(span, err)
+
+	return rows, err
+}
+
+func (t tracedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	row := t.db.QueryRowContext(ctx, query, args...)
+	End(span, row.Err())
+
+	return row
+}
+
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	name := QueryName(query)
+
+	return Tracer().Start(ctx, "sql "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "mysql"),
+			attribute.String("db.operation.name", name),
+		),
+	)
+}
+
+// QueryName returns the sqlc query name from the leading "-- name:" comment, or "query".
+func QueryName(query string) string {
+	rest, ok := strings.CutPrefix(query, queryNamePrefix)
+	if !ok {
+		return "query"
+	}
+
+	name, _, _ := strings.Cut(rest, " ")
+
+	return name
+}
@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"biblebrain-services/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies the spans created by this module.
+const InstrumentationName = "biblebrain-services"
+
+// ErrUnknownExporter is returned by Setup for an unsupported exporter name.
+var ErrUnknownExporter = errors.New("unknown tracing exporter")
+
+// Provider owns the SDK tracer provider installed by Setup. A Provider for the "none"
+// exporter holds nothing and all its methods are no-ops.
+type Provider struct {
+	tp *sdktrace.TracerProvider
+}
+
+// Setup installs the global tracer provider and W3C trace-context propagator described by cfg.
+// Spans are exported over OTLP/HTTP, printed to stdout for local runs, or dropped.
+func Setup(ctx context.Context, cfg config.Tracing) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+
+	var err error
+
+	switch cfg.Exporter {
+	case config.TracingExporterNone:
+		return &Provider{}, nil
+	case config.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.TracingExporterOTLP:
+		var opts []otlptracehttp.Option
+		// Without an explicit endpoint the exporter honours the OTEL_EXPORTER_OTLP_* variables.
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExporter, cfg.Exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("creating %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("building trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return &Provider{tp: tp}, nil
+}
+
+// ForceFlush exports all finished spans. Lambda calls it before each invocation returns,
+// because the container may be frozen before the batcher runs.
+func (p *Provider) ForceFlush(ctx context.Context) error {
+	if p.tp == nil {
+		return nil
+	}
+
+	if err := p.tp.ForceFlush(ctx); err != nil {
+		return fmt.Errorf("flushing spans: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown flushes and stops the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.tp == nil {
+		return nil
+	}
+
+	if err := p.tp.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutting down tracer provider: %w", err)
+	}
+
+	return nil
+}
+
+// Tracer returns the tracer of this module from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
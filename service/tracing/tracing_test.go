@@ -0,0 +1,65 @@
+package tracing_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	tracing_service "biblebrain-services/service/tracing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errQueryFailed = errors.New("query failed")
+
+// failingDBTX fails every call.
+type failingDBTX struct{}
+
+func (failingDBTX) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errQueryFailed
+}
+
+func (failingDBTX) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errQueryFailed
+}
+
+func (failingDBTX) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errQueryFailed
+}
+
+func (failingDBTX) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return &sql.Row{}
+}
+
+// TestQueryName verifies that the sqlc query name is read from the leading comment.
+func TestQueryName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "GetOrganizations", tracing_service.QueryName("-- name: GetOrganizations :many\nSELECT 1"))
+	require.Equal(t, "query", tracing_service.QueryName("SELECT 1"))
+}
+
+// TestWrapDBTX verifies that every query gets a named span and failures are recorded on it.
+func TestWrapDBTX(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db := tracing_service.WrapDBTX(failingDBTX{})
+
+	_, err := db.QueryContext(t.Context(), "-- name: GetFilesetCopyrights :many\nSELECT 1")
+	require.ErrorIs(t, err, errQueryFailed)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "sql GetFilesetCopyrights", spans[0].Name())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
@@ -16,34 +16,42 @@ const getOrganizations = `-- name: GetOrganizations :many
 SELECT 
     distinct bfco.organization_id,
     o.slug as organization_slug,
-    ot.name as organization_name, ol.url as organization_logo_url
+    ot.name as organization_name, ol.url as organization_logo_url,
+    o.primaryColor as organization_color
 FROM bible_fileset_copyright_organizations as bfco
 JOIN organizations o ON o.id = bfco.organization_id
 INNER JOIN organization_translations ot ON ot.organization_id = o.id
 LEFT JOIN organization_logos ol ON ol.organization_id = o.id AND ol.icon IS FALSE
 WHERE bfco.organization_id IN (/*SLICE:organizationsId*/?)
-AND ot.language_id = 6414
+AND ot.language_id = ?
 ORDER BY organization_name
 `
 
+type GetOrganizationsParams struct {
+	OrganizationsId []uint32 `json:"organizationsId"`
+	LanguageId      uint32   `json:"languageId"`
+}
+
 type GetOrganizationsRow struct {
 	OrganizationID      uint32         `json:"organization_id"`
 	OrganizationSlug    string         `json:"organization_slug"`
 	OrganizationName    string         `json:"organization_name"`
 	OrganizationLogoUrl sql.NullString `json:"organization_logo_url"`
+	OrganizationColor   sql.NullString `json:"organization_color"`
 }
 
-func (q *Queries) GetOrganizations(ctx context.Context, organizationsid []uint32) ([]GetOrganizationsRow, error) {
+func (q *Queries) GetOrganizations(ctx context.Context, arg GetOrganizationsParams) ([]GetOrganizationsRow, error) {
 	query := getOrganizations
 	var queryParams []interface{}
-	if len(organizationsid) > 0 {
-		for _, v := range organizationsid {
+	if len(arg.OrganizationsId) > 0 {
+		for _, v := range arg.OrganizationsId {
 			queryParams = append(queryParams, v)
 		}
-		query = strings.Replace(query, "/*SLICE:organizationsId*/?", strings.Repeat(",?", len(organizationsid))[1:], 1)
+		query = strings.Replace(query, "/*SLICE:organizationsId*/?", strings.Repeat(",?", len(arg.OrganizationsId))[1:], 1)
 	} else {
 		query = strings.Replace(query, "/*SLICE:organizationsId*/?", "NULL", 1)
 	}
+	queryParams = append(queryParams, arg.LanguageId)
 	rows, err := q.db.QueryContext(ctx, query, queryParams...)
 	if err != nil {
 		return nil, err
@@ -57,6 +65,7 @@ func (q *Queries) GetOrganizations(ctx context.Context, organizationsid []uint32
 			&i.OrganizationSlug,
 			&i.OrganizationName,
 			&i.OrganizationLogoUrl,
+			&i.OrganizationColor,
 		); err != nil {
 			return nil, err
 		}
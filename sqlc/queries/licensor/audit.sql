@@ -0,0 +1,52 @@
+-- name: ListFilesetCopyrightsForAudit :many
+SELECT 
+    GROUP_CONCAT(DISTINCT bfco.organization_id) AS organization_id_list, bible_fileset_copyrights.copyright_date,
+    bible_fileset_copyrights.copyright,
+    bft.description product_code
+FROM bible_fileset_copyrights
+LEFT JOIN bible_fileset_copyright_organizations bfco ON bfco.hash_id = bible_fileset_copyrights.hash_id
+JOIN bible_fileset_tags bft ON bft.hash_id = bible_fileset_copyrights.hash_id AND bft.name = 'stock_no'
+WHERE EXISTS (
+    SELECT 1
+    FROM bible_filesets bf
+    WHERE bf.hash_id = bible_fileset_copyrights.hash_id
+    AND bf.set_type_code IN (sqlc.slice('typeCodes'))
+)
+GROUP BY
+    bible_fileset_copyrights.copyright_date,
+    bible_fileset_copyrights.copyright,
+    bft.description
+ORDER BY product_code;
+
+-- name: GetFilesetCopyrightsForAudit :many
+SELECT 
+    GROUP_CONCAT(DISTINCT bfco.organization_id) AS organization_id_list, bible_fileset_copyrights.copyright_date,
+    bible_fileset_copyrights.copyright,
+    bft.description product_code
+FROM bible_fileset_copyrights
+LEFT JOIN bible_fileset_copyright_organizations bfco ON bfco.hash_id = bible_fileset_copyrights.hash_id
+JOIN bible_fileset_tags bft ON bft.hash_id = bible_fileset_copyrights.hash_id AND bft.name = 'stock_no'
+WHERE bft.description IN (sqlc.slice('productCodes'))
+AND EXISTS (
+    SELECT 1
+    FROM bible_filesets bf
+    WHERE bf.hash_id = bible_fileset_copyrights.hash_id
+    AND bf.set_type_code IN (sqlc.slice('typeCodes'))
+)
+GROUP BY
+    bible_fileset_copyrights.copyright_date,
+    bible_fileset_copyrights.copyright,
+    bft.description
+ORDER BY product_code;
+
+-- name: GetOrganizationsForAudit :many
+SELECT 
+    o.id AS organization_id,
+    o.slug AS organization_slug,
+    ot.name AS organization_name,
+    ol.url AS organization_logo_url
+FROM organizations o
+LEFT JOIN organization_translations ot ON ot.organization_id = o.id AND ot.language_id = sqlc.arg('languageId')
+LEFT JOIN organization_logos ol ON ol.organization_id = o.id AND ol.icon IS FALSE
+WHERE o.id IN (sqlc.slice('organizationsId'))
+ORDER BY o.id;
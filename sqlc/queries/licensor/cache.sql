@@ -0,0 +1,23 @@
+-- name: GetCopyrightsVersion :one
+SELECT
+    COUNT(*) AS row_count,
+    GREATEST(
+        MAX(bible_fileset_copyrights.updated_at),
+        MAX(bfco.updated_at),
+        MAX(o.updated_at),
+        COALESCE(MAX(ot.updated_at), MAX(o.updated_at)),
+        COALESCE(MAX(ol.updated_at), MAX(o.updated_at))
+    ) AS updated_at
+FROM bible_fileset_copyrights
+JOIN bible_fileset_tags bft ON bft.hash_id = bible_fileset_copyrights.hash_id AND bft.name = 'stock_no'
+JOIN bible_fileset_copyright_organizations bfco ON bfco.hash_id = bible_fileset_copyrights.hash_id
+JOIN organizations o ON o.id = bfco.organization_id
+LEFT JOIN organization_translations ot ON ot.organization_id = o.id AND ot.language_id = sqlc.arg('languageId')
+LEFT JOIN organization_logos ol ON ol.organization_id = o.id AND ol.icon IS FALSE
+WHERE bft.description IN (sqlc.slice('productCodes'))
+AND EXISTS (
+    SELECT 1
+    FROM bible_filesets bf
+    WHERE bf.hash_id = bible_fileset_copyrights.hash_id
+    AND bf.set_type_code IN (sqlc.slice('typeCodes'))
+);
@@ -0,0 +1,14 @@
+-- name: ListOrganizationLogos :many
+SELECT DISTINCT ol.organization_id, ol.url
+FROM organization_logos ol
+WHERE ol.icon IS FALSE
+AND ol.url IS NOT NULL AND ol.url <> ''
+ORDER BY ol.url, ol.organization_id;
+
+-- name: GetOrganizationLogos :many
+SELECT DISTINCT ol.organization_id, ol.url
+FROM organization_logos ol
+WHERE ol.icon IS FALSE
+AND ol.url IS NOT NULL AND ol.url <> ''
+AND ol.organization_id IN (sqlc.slice('organizationsId'))
+ORDER BY ol.url, ol.organization_id;
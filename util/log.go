@@ -1,6 +1,8 @@
 package util
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
 )
@@ -11,7 +13,25 @@ const (
 	LogLevelDebug = "Debug"
 )
 
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Logger returns a text slog.Logger with the default Redactor. See NewLogger.
 func Logger(logLevelEnv string) *slog.Logger {
+	return NewLogger(logLevelEnv, LogFormatText, DefaultRedactor())
+}
+
+// NewLogger returns a slog.Logger writing to stdout at the given level and format whose
+// attributes pass through redactor.
+func NewLogger(logLevelEnv, format string, redactor *Redactor) *slog.Logger {
+	return slog.New(NewHandler(os.Stdout, logLevelEnv, format, redactor))
+}
+
+// NewHandler returns a text or JSON slog.Handler writing to w. JSON output is meant for
+// CloudWatch Logs Insights; any format other than LogFormatJSON produces text.
+func NewHandler(w io.Writer, logLevelEnv, format string, redactor *Redactor) slog.Handler {
 	// Set log level based on environment
 	var logLevel slog.Level
 
@@ -26,12 +46,31 @@ func Logger(logLevelEnv string) *slog.Logger {
 		logLevel = slog.LevelError
 	}
 
-	// Set up the logger with the chosen log level
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+	opts := &slog.HandlerOptions{
+		Level:       logLevel,
+		ReplaceAttr: redactor.ReplaceAttr,
+	}
+
+	if format == LogFormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.NewTextHandler(w, opts)
+}
+
+type loggerKey struct{}
 
-	return logger
+// WithLogger returns a copy of ctx carrying logger, typically slog.Default() enriched with
+// request-scoped attributes such as the request ID.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
 }
 
-// Logger returns a slog.Logger instance configured with the specified log level.
+// LoggerFrom returns the logger stored in ctx by WithLogger, or slog.Default().
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,31 @@
+package util_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	util "biblebrain-services/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSONHandlerWithContextLogger verifies JSON output, redaction and context-scoped attributes.
+func TestJSONHandlerWithContextLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(util.NewHandler(&buf, util.LogLevelInfo, util.LogFormatJSON, util.DefaultRedactor()))
+
+	ctx := util.WithLogger(t.Context(), logger.With("request_id", "req-1"))
+	util.LoggerFrom(ctx).Info("fetching organizations", "apiKey", "abc123")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "fetching organizations", record["msg"])
+	require.Equal(t, "req-1", record["request_id"])
+	require.Equal(t, util.RedactedValue, record["apiKey"])
+
+	require.Same(t, slog.Default(), util.LoggerFrom(t.Context()))
+}
@@ -0,0 +1,174 @@
+package util
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// RedactedValue replaces sensitive or non allow-listed values in logs.
+const RedactedValue = "[REDACTED]"
+
+// DefaultMaxInListArgs is how many placeholders or arguments of an expanded IN-list are logged
+// before the rest is summarized.
+const DefaultMaxInListArgs = 10
+
+// DefaultLogAttrs lists the attribute keys that may be logged as-is. Any other attribute is
+// logged with its value replaced by RedactedValue, so new keys must be added here explicitly.
+func DefaultLogAttrs() []string {
+	return []string{
+		// slog built-ins
+		slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey,
+		// application attributes
+		"addr", "check", "code", "command", "commit", "data", "dsn", "err", "error", "image", "job_id", "level",
+		"method", "mode", "params", "path", "products", "reason", "request_id", "secret", "size", "status",
+		"timeout", "trace_id", "url", "version",
+	}
+}
+
+// DefaultSensitiveArgPatterns matches query argument values that must never be logged:
+// DSNs with credentials, password or token assignments, and bearer tokens.
+func DefaultSensitiveArgPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		regexp.MustCompile(`[^\s:/@]+:[^\s@]+@(tcp|unix)\(`),
+		regexp.MustCompile(`(?i)(password|passwd|pwd|secret|token)\s*[=:]`),
+		regexp.MustCompile(`(?i)^bearer\s+\S+`),
+	}
+}
+
+// dsnPasswordPattern matches the password part of a user:password@ prefix.
+var dsnPasswordPattern = regexp.MustCompile(`^([^:@/]*):([^@]*)@`)
+
+// inListPattern matches a run of two or more comma-separated placeholders, as produced by
+// sqlc.slice expansions.
+var inListPattern = regexp.MustCompile(`\?(\s*,\s*\?)+`)
+
+// RedactDSN masks the password of a MySQL DSN. Unparsable input is masked by pattern.
+func RedactDSN(dsn string) string {
+	config, err := mysql.ParseDSN(dsn)
+	if err == nil {
+		if config.Passwd != "" {
+			config.Passwd = RedactedValue
+		}
+
+		return config.FormatDSN()
+	}
+
+	return dsnPasswordPattern.ReplaceAllString(dsn, "$1:"+RedactedValue+"@")
+}
+
+// Redactor removes credentials and noise from log records.
+type Redactor struct {
+	// AllowedAttrs are the attribute keys whose values may be logged.
+	AllowedAttrs map[string]struct{}
+	// SensitiveArgPatterns mask query arguments whose string form matches any pattern.
+	SensitiveArgPatterns []*regexp.Regexp
+	// MaxInListArgs truncates long placeholder lists and argument lists.
+	MaxInListArgs int
+}
+
+// NewRedactor returns a Redactor allowing the given attribute keys.
+func NewRedactor(allowedAttrs []string, sensitiveArgPatterns []*regexp.Regexp, maxInListArgs int) *Redactor {
+	allowed := make(map[string]struct{}, len(allowedAttrs))
+	for _, key := range allowedAttrs {
+		allowed[key] = struct{}{}
+	}
+
+	return &Redactor{
+		AllowedAttrs:         allowed,
+		SensitiveArgPatterns: sensitiveArgPatterns,
+		MaxInListArgs:        maxInListArgs,
+	}
+}
+
+// DefaultRedactor returns a Redactor with the default allow-list and patterns.
+func DefaultRedactor() *Redactor {
+	return NewRedactor(DefaultLogAttrs(), DefaultSensitiveArgPatterns(), DefaultMaxInListArgs)
+}
+
+// ReplaceAttr is a slog.HandlerOptions.ReplaceAttr function. Attributes inside a group are
+// allowed when the top-level group is allowed; DSNs are always logged with their password masked.
+func (r *Redactor) ReplaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	key := attr.Key
+	if len(groups) > 0 {
+		key = groups[0]
+	}
+
+	if _, ok := r.AllowedAttrs[key]; !ok {
+		return slog.String(attr.Key, RedactedValue)
+	}
+
+	if attr.Key == "dsn" {
+		return slog.String(attr.Key, RedactDSN(attr.Value.String()))
+	}
+
+	return attr
+}
+
+// RedactQuery collapses expanded IN-lists longer than MaxInListArgs placeholders.
+func (r *Redactor) RedactQuery(query string) string {
+	return inListPattern.ReplaceAllStringFunc(query, func(list string) string {
+		count := strings.Count(list, "?")
+		if r.MaxInListArgs <= 0 || count <= r.MaxInListArgs {
+			return list
+		}
+
+		return strings.Repeat("?,", r.MaxInListArgs) + fmt.Sprintf("... (%d more)", count-r.MaxInListArgs)
+	})
+}
+
+// RedactArgs masks sensitive query arguments and truncates the list to MaxInListArgs entries.
+func (r *Redactor) RedactArgs(args []any) []any {
+	limit := len(args)
+	if r.MaxInListArgs > 0 && limit > r.MaxInListArgs {
+		limit = r.MaxInListArgs
+	}
+
+	out := make([]any, 0, limit+1)
+
+	for _, arg := range args[:limit] {
+		out = append(out, r.redactArg(arg))
+	}
+
+	if limit < len(args) {
+		out = append(out, fmt.Sprintf("... (%d more)", len(args)-limit))
+	}
+
+	return out
+}
+
+func (r *Redactor) redactArg(arg any) any {
+	text := fmt.Sprint(arg)
+	for _, pattern := range r.SensitiveArgPatterns {
+		if pattern.MatchString(text) {
+			return RedactedValue
+		}
+	}
+
+	return arg
+}
+
+// RedactQueryData redacts the query, args and error fields of a sqldb-logger data map.
+func (r *Redactor) RedactQueryData(data map[string]any) map[string]any {
+	out := make(map[string]any, len(data))
+
+	for key, value := range data {
+		switch typed := value.(type) {
+		case string:
+			if key == "query" {
+				out[key] = r.RedactQuery(typed)
+			} else {
+				out[key] = r.redactArg(typed)
+			}
+		case []any:
+			out[key] = r.RedactArgs(typed)
+		default:
+			out[key] = value
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,67 @@
+package util_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	util "biblebrain-services/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedactDSN verifies that passwords are masked in parsable and unparsable DSNs.
+func TestRedactDSN(t *testing.T) {
+	t.Parallel()
+
+	redacted := util.RedactDSN("admin:s3cret@tcp(db.internal:3306)/dbp")
+	assert.NotContains(t, redacted, "s3cret")
+	assert.Contains(t, redacted, "admin:"+util.RedactedValue+"@tcp(db.internal:3306)/dbp")
+
+	assert.Equal(t, "admin:"+util.RedactedValue+"@not a dsn", util.RedactDSN("admin:s3cret@not a dsn"))
+}
+
+// TestRedactorReplaceAttr verifies allow-listing and DSN masking in the slog handler.
+func TestRedactorReplaceAttr(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	redactor := util.DefaultRedactor()
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redactor.ReplaceAttr}))
+
+	logger.Info("connecting",
+		"dsn", "admin:s3cret@tcp(db.internal:3306)/dbp",
+		"url", "https://example.com/logo.png",
+		"code", "DATABASE_ERROR",
+		"apiKey", "abc123",
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "s3cret")
+	assert.NotContains(t, out, "abc123")
+	assert.Contains(t, out, "apiKey="+util.RedactedValue)
+	assert.Contains(t, out, "url=https://example.com/logo.png")
+	assert.Contains(t, out, "code=DATABASE_ERROR")
+	assert.Contains(t, out, "msg=connecting")
+}
+
+// TestRedactQueryData verifies IN-list truncation and sensitive argument masking.
+func TestRedactQueryData(t *testing.T) {
+	t.Parallel()
+
+	redactor := util.NewRedactor(util.DefaultLogAttrs(), util.DefaultSensitiveArgPatterns(), 3)
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", 5), ",")
+
+	data := redactor.RedactQueryData(map[string]any{
+		"query":    "SELECT * FROM t WHERE a = ? AND id IN (" + placeholders + ")",
+		"args":     []any{"password=hunter2", 1, 2, 3, 4, 5},
+		"duration": "1.2ms",
+	})
+
+	assert.Equal(t, "SELECT * FROM t WHERE a = ? AND id IN (?,?,?,... (2 more))", data["query"])
+	require.IsType(t, []any{}, data["args"])
+	assert.Equal(t, []any{util.RedactedValue, 1, 2, "... (3 more)"}, data["args"])
+	assert.Equal(t, "1.2ms", data["duration"])
+}
@@ -0,0 +1,29 @@
+package util
+
+import "runtime/debug"
+
+// Build information, injected at link time:
+//
+//	go build -ldflags "-X biblebrain-services/util.Version=v1.2.3 -X biblebrain-services/util.Commit=$(git rev-parse HEAD)"
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// BuildInfo returns the build version and git commit. When Commit was not injected it falls
+// back to the VCS revision recorded by the Go toolchain, or "unknown".
+func BuildInfo() (string, string) {
+	if Commit != "" {
+		return Version, Commit
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return Version, setting.Value
+			}
+		}
+	}
+
+	return Version, "unknown"
+}